@@ -0,0 +1,142 @@
+// args.go
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// cliFlags holds the --options pulled out of the command line, keyed by name
+// without dashes. Repeated options keep every value in order.
+type cliFlags map[string][]string
+
+// valueFlags lists the options that consume the following argument as their
+// value when not written as --name=value. All other options are boolean.
+var valueFlags = map[string]bool{
+	"from-json":   true,
+	"openapi":     true,
+	"tag":         true,
+	"repo":        true,
+	"params":      true,
+	"returns":     true,
+	"kind":        true,
+	"usecase":     true,
+	"state":       true,
+	"path-param":  true,
+	"query-param": true,
+	"parent":      true,
+	"format":      true,
+	"level":       true,
+	"feature":     true,
+}
+
+// optionalValueFlags lists the options that take the following argument as
+// their value only when it is one of the listed words, so "--shell stateful"
+// and a bare "--shell" both work.
+var optionalValueFlags = map[string][]string{
+	"shell": {shellPlain, shellStateful},
+}
+
+// boolFlags lists the options that take no value.
+var boolFlags = map[string]bool{
+	"dry-run":  true,
+	"force":    true,
+	"check":    true,
+	"json":     true,
+	"shell":    true,
+	"stateful": true,
+	"consumer": true,
+	"export":   true,
+}
+
+// maxArgs is the number of positional arguments a command takes at most,
+// keyed by command and subcommand. Commands taking field specs or template
+// names take any number and are not listed.
+var maxArgs = map[string]int{
+	"new feature":    3,
+	"new page":       4,
+	"new provider":   4,
+	"new cubit":      4,
+	"new bloc":       4,
+	"new widget":     4,
+	"new usecase":    4,
+	"new repository": 4,
+	"new datasource": 4,
+	"remove feature": 3,
+	"remove page":    4,
+	"rename feature": 4,
+	"rename page":    5,
+	"sync routes":    2,
+	"migrate tests":  2,
+	"release apk":    2,
+	"release capk":   2,
+	"lint":           1,
+	"graph":          1,
+	"list":           2,
+	"inspect":        2,
+}
+
+// checkArgCount fails when a command gets more positional arguments than it
+// reads, since they would be silently ignored.
+func checkArgCount(args []string) error {
+	key := args[0]
+	if len(args) > 1 {
+		if _, ok := maxArgs[key+" "+args[1]]; ok {
+			key += " " + args[1]
+		}
+	}
+	if n, ok := maxArgs[key]; ok && len(args) > n {
+		return fmt.Errorf("unexpected argument %q for '%s'", args[n], key)
+	}
+	return nil
+}
+
+// parseArgs separates positional arguments from --options. Options may appear
+// anywhere on the command line; unknown ones are an error.
+func parseArgs(args []string) ([]string, cliFlags, error) {
+	positional := make([]string, 0, len(args))
+	flags := cliFlags{}
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if !strings.HasPrefix(arg, "--") || arg == "--" {
+			positional = append(positional, arg)
+			continue
+		}
+		name := strings.TrimPrefix(arg, "--")
+		k, v, hasValue := strings.Cut(name, "=")
+		if !valueFlags[k] && !boolFlags[k] {
+			return nil, nil, fmt.Errorf("unknown option --%s", k)
+		}
+		if hasValue {
+			flags[k] = append(flags[k], v)
+			continue
+		}
+		if valueFlags[name] && i+1 < len(args) {
+			flags[name] = append(flags[name], args[i+1])
+			i++
+			continue
+		}
+		if i+1 < len(args) && containsString(optionalValueFlags[name], args[i+1]) {
+			flags[name] = append(flags[name], args[i+1])
+			i++
+			continue
+		}
+		flags[name] = append(flags[name], "")
+	}
+	return positional, flags, nil
+}
+
+// has reports whether the option was given.
+func (f cliFlags) has(name string) bool {
+	_, ok := f[name]
+	return ok
+}
+
+// value returns the last value given for the option, or "".
+func (f cliFlags) value(name string) string {
+	v := f[name]
+	if len(v) == 0 {
+		return ""
+	}
+	return v[len(v)-1]
+}
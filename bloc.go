@@ -0,0 +1,109 @@
+// bloc.go
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+)
+
+// State-management strategies selectable with the "state_management" config
+// key or the --state flag.
+const (
+	stateRiverpod = "riverpod"
+	stateBloc     = "bloc"
+)
+
+// Kinds generated by the bloc strategy.
+const (
+	blocCubit = "cubit"
+	blocBloc  = "bloc"
+)
+
+// parseBlocKind validates a --kind value for the bloc strategy; an empty
+// value means cubit.
+func parseBlocKind(s string) (string, error) {
+	switch s {
+	case "", blocCubit:
+		return blocCubit, nil
+	case blocBloc:
+		return blocBloc, nil
+	}
+	return "", fmt.Errorf("unknown bloc kind %q (want %s, %s)", s, blocCubit, blocBloc)
+}
+
+func blocDir(feature string) string {
+	return filepath.Join("lib", "features", feature, "presentation", "bloc")
+}
+
+// blocFile is the file declaring the cubit or bloc class; state and event
+// files are parts of it.
+func blocFile(feature, name, kind string) string {
+	return filepath.Join(blocDir(feature), name+"_"+kind+".dart")
+}
+
+// createBloc writes a cubit (with its state) or a bloc (with its events and
+// state) under presentation/bloc, and a bloc_test test.
+func createBloc(feature, name, kind string) {
+	dir := blocDir(feature)
+	changes.mkdirAll(dir)
+
+	file := blocFile(feature, name, kind)
+	data := templateData{Feature: feature, Name: name, Kind: kind}
+	writeTemplate(file, kind+".dart.tmpl", data)
+
+	data.PartFile = filepath.Base(file)
+	writeTemplate(filepath.Join(dir, name+"_state.dart"), "bloc_state.dart.tmpl", data)
+	if kind == blocBloc {
+		writeTemplate(filepath.Join(dir, name+"_event.dart"), "bloc_event.dart.tmpl", data)
+	}
+
+	var ok bool
+	if data.ProviderImport, ok = testImport(file); !ok {
+		return
+	}
+	content, err := renderTemplate(kind+"_test.dart.tmpl", data)
+	if err != nil {
+		failf("Failed rendering %s test: %v", kind, err)
+		return
+	}
+	writeTest(feature, filepath.Join("presentation", "bloc", name+"_"+kind+"_test.dart"), content)
+
+	for _, dep := range []string{"flutter_bloc", "bloc_test"} {
+		if !pubspecHasDependency(dep) {
+			fmt.Printf("⚠️  The generated %s uses package:%s; add %s to pubspec.yaml\n", kind, dep, dep)
+		}
+	}
+}
+
+// findBloc returns the cubit or bloc named name, if either exists.
+func findBloc(feature, name string) (string, string, bool) {
+	for _, kind := range []string{blocCubit, blocBloc} {
+		if file := blocFile(feature, name, kind); changes.exists(file) {
+			return file, kind, true
+		}
+	}
+	return "", "", false
+}
+
+// writeBlocPage writes a page that provides and builds on a cubit or bloc:
+// the one named after the page, else the feature's, created as a cubit when
+// missing.
+func writeBlocPage(feature, pageName, file string, fields []dartField) {
+	name := pageName
+	blocPath, kind, ok := findBloc(feature, name)
+	if !ok {
+		name = feature
+		if blocPath, kind, ok = findBloc(feature, name); !ok {
+			createBloc(feature, name, blocCubit)
+			blocPath, kind = blocFile(feature, name, blocCubit), blocCubit
+		}
+	}
+	writeTemplate(file, "page_bloc.dart.tmpl", templateData{
+		Feature:        feature,
+		Name:           pageName,
+		Kind:           kind,
+		Provider:       name,
+		ProviderImport: dartRelImport(file, blocPath),
+		Fields:         fields,
+	})
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBlocStateManagement(t *testing.T) {
+	withTempDir(t)
+	if err := os.WriteFile(".farch.yaml", []byte("state_management: bloc\n"), 0644); err != nil {
+		t.Fatalf("write config failed: %v", err)
+	}
+	_ = runMain(t, "new", "feature", "orders")
+
+	presentation := filepath.Join("lib", "features", "orders", "presentation")
+	mustNotExist(t, filepath.Join(presentation, "providers"))
+	mustNotExist(t, filepath.Join("test", "features", "orders", "presentation", "providers"))
+	cubit := mustReadFile(t, filepath.Join(presentation, "bloc", "orders_cubit.dart"))
+	for _, expect := range []string{"part 'orders_state.dart';", "class OrdersCubit extends Cubit<OrdersState> {"} {
+		if !strings.Contains(cubit, expect) {
+			t.Fatalf("expected cubit to contain %q, got:\n%s", expect, cubit)
+		}
+	}
+	if state := mustReadFile(t, filepath.Join(presentation, "bloc", "orders_state.dart")); !strings.HasPrefix(state, "part of 'orders_cubit.dart';\n") {
+		t.Fatalf("unexpected state file:\n%s", state)
+	}
+	test := mustReadFile(t, filepath.Join("test", "features", "orders", "presentation", "bloc", "orders_cubit_test.dart"))
+	if !strings.Contains(test, "import 'package:bloc_test/bloc_test.dart';") || !strings.Contains(test, "blocTest<OrdersCubit, OrdersState>(") {
+		t.Fatalf("expected a bloc_test test, got:\n%s", test)
+	}
+	page := mustReadFile(t, filepath.Join(presentation, "pages", "orders_page.dart"))
+	for _, expect := range []string{
+		"import '../bloc/orders_cubit.dart';",
+		"class OrdersPage extends StatelessWidget {",
+		"create: (_) => OrdersCubit(),",
+		"body: BlocBuilder<OrdersCubit, OrdersState>(",
+		"onPressed: () => context.read<OrdersCubit>().increment(),",
+	} {
+		if !strings.Contains(page, expect) {
+			t.Fatalf("expected page to contain %q, got:\n%s", expect, page)
+		}
+	}
+	if strings.Contains(page, "riverpod") {
+		t.Fatalf("bloc page should not use riverpod:\n%s", page)
+	}
+
+	_ = runMain(t, "new", "provider", "orders", "cart", "--kind", "bloc")
+	_ = runMain(t, "new", "page", "orders", "cart")
+	mustExist(t, filepath.Join(presentation, "bloc", "cart_event.dart"))
+	page = mustReadFile(t, filepath.Join(presentation, "pages", "cart_page.dart"))
+	if !strings.Contains(page, "context.read<CartBloc>().add(const CartIncremented())") {
+		t.Fatalf("expected page to add the bloc event, got:\n%s", page)
+	}
+}
+
+func TestStateFlagOverridesConfig(t *testing.T) {
+	withTempDir(t)
+	_ = runMain(t, "new", "page", "orders", "orders", "--state", "bloc")
+	mustExist(t, filepath.Join("lib", "features", "orders", "presentation", "bloc", "orders_cubit.dart"))
+	mustNotExist(t, filepath.Join("lib", "features", "orders", "presentation", "providers", "orders_provider.dart"))
+
+	out, code := runMainCode(t, "new", "page", "orders", "cart", "--state", "mobx")
+	if code == 0 || !strings.Contains(out, "unknown state_management") {
+		t.Fatalf("expected an invalid --state to fail, got %d:\n%s", code, out)
+	}
+}
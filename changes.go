@@ -0,0 +1,428 @@
+// changes.go
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// changeSet stages every directory and file change a command makes. Reads go
+// through it so later steps see earlier staged writes. Nothing touches the
+// disk until commit; a dry run prints the plan instead, and a failed step
+// discards everything.
+type changeSet struct {
+	dirs      []string
+	newDirs   map[string]bool
+	rmDirs    []string
+	files     map[string]*stagedFile
+	order     []string
+	skipped   []string
+	generated map[string]bool
+	moved     map[string]string
+	errs      []error
+	dryRun    bool
+}
+
+type stagedFile struct {
+	path    string
+	existed bool
+	before  []byte
+	after   []byte
+	deleted bool
+}
+
+// changes is the change set of the running command.
+var changes = newChangeSet()
+
+func newChangeSet() *changeSet {
+	return &changeSet{
+		newDirs:   map[string]bool{},
+		files:     map[string]*stagedFile{},
+		generated: map[string]bool{},
+		moved:     map[string]string{},
+	}
+}
+
+// failf reports a failed step and marks the running command as failed, so its
+// staged changes are rolled back instead of committed.
+func failf(format string, a ...any) {
+	err := fmt.Errorf(format, a...)
+	fmt.Printf("❌ %v\n", err)
+	changes.errs = append(changes.errs, err)
+}
+
+// createdf reports a file or directory the command creates. A dry run stays
+// quiet, since its plan lists what would be created.
+func (c *changeSet) createdf(format string, a ...any) {
+	if !c.dryRun {
+		fmt.Printf(format, a...)
+	}
+}
+
+// failed reports whether any step of the command failed.
+func (c *changeSet) failed() bool {
+	return len(c.errs) > 0
+}
+
+// mkdirAll stages dir and any missing parents.
+func (c *changeSet) mkdirAll(dir string) {
+	dir = filepath.Clean(dir)
+	if dir == "." || dir == string(filepath.Separator) || c.newDirs[dir] {
+		return
+	}
+	if info, err := os.Stat(dir); err == nil && info.IsDir() {
+		return
+	}
+	c.mkdirAll(filepath.Dir(dir))
+	c.newDirs[dir] = true
+	c.dirs = append(c.dirs, dir)
+}
+
+// exists reports whether path exists once the staged changes are applied.
+func (c *changeSet) exists(path string) bool {
+	path = filepath.Clean(path)
+	if f, ok := c.files[path]; ok {
+		return !f.deleted
+	}
+	if c.newDirs[path] {
+		return true
+	}
+	for _, dir := range c.rmDirs {
+		if dir == path {
+			return false
+		}
+	}
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// readFile returns the staged content of path, falling back to the disk.
+func (c *changeSet) readFile(path string) ([]byte, error) {
+	path = filepath.Clean(path)
+	if f, ok := c.files[path]; ok {
+		if f.deleted {
+			return nil, &fs.PathError{Op: "open", Path: path, Err: fs.ErrNotExist}
+		}
+		return append([]byte(nil), f.after...), nil
+	}
+	return os.ReadFile(path)
+}
+
+// file returns the staged entry for path, recording its current disk state
+// the first time it is touched.
+func (c *changeSet) file(path string) *stagedFile {
+	path = filepath.Clean(path)
+	if f, ok := c.files[path]; ok {
+		return f
+	}
+	f := &stagedFile{path: path}
+	if data, err := os.ReadFile(path); err == nil {
+		f.existed = true
+		f.before = data
+		f.after = data
+	}
+	c.files[path] = f
+	c.order = append(c.order, path)
+	return f
+}
+
+// writeFile stages content for path, creating parent directories as needed.
+func (c *changeSet) writeFile(path string, content []byte) error {
+	if info, err := os.Stat(path); err == nil && info.IsDir() {
+		return fmt.Errorf("%s is a directory", path)
+	}
+	c.mkdirAll(filepath.Dir(path))
+	f := c.file(path)
+	f.after = append([]byte(nil), content...)
+	f.deleted = false
+	return nil
+}
+
+// remove stages the deletion of the file at path.
+func (c *changeSet) remove(path string) error {
+	if !c.exists(path) {
+		return &fs.PathError{Op: "remove", Path: path, Err: fs.ErrNotExist}
+	}
+	f := c.file(path)
+	f.after = nil
+	f.deleted = true
+	return nil
+}
+
+// rename stages moving the file at src to dst.
+func (c *changeSet) rename(src, dst string) error {
+	data, err := c.readFile(src)
+	if err != nil {
+		return err
+	}
+	if err := c.writeFile(dst, data); err != nil {
+		return err
+	}
+	c.moved[filepath.Clean(dst)] = filepath.Clean(src)
+	return c.remove(src)
+}
+
+// removeDir stages the removal of an empty directory. Callers stage the
+// removal of its files first and pass nested directories before parents.
+func (c *changeSet) removeDir(dir string) {
+	c.rmDirs = append(c.rmDirs, filepath.Clean(dir))
+}
+
+// markGenerated records path as farch output, so its hash is kept in the
+// manifest.
+func (c *changeSet) markGenerated(path string) {
+	c.generated[filepath.Clean(path)] = true
+}
+
+// skip records that path was left untouched because it already exists.
+func (c *changeSet) skip(path string) {
+	c.skipped = append(c.skipped, filepath.Clean(path))
+}
+
+// changed reports whether the staged entry differs from the disk.
+func (f *stagedFile) changed() bool {
+	if f.deleted {
+		return f.existed
+	}
+	return !f.existed || !bytes.Equal(f.before, f.after)
+}
+
+// pending returns the directories and files the change set would create,
+// edit or delete, in staging order.
+func (c *changeSet) pending() []string {
+	out := append([]string(nil), c.dirs...)
+	for _, path := range c.order {
+		if c.files[path].changed() {
+			out = append(out, path)
+		}
+	}
+	return append(out, c.rmDirs...)
+}
+
+// commit applies the staged changes atomically. Every new file body is first
+// written to a temp file next to its target, then all temp files are renamed
+// into place. If any step fails, everything already applied is restored and
+// the created directories are removed again.
+func (c *changeSet) commit() error {
+	var createdDirs, removedDirs []string
+	temps := map[string]string{}
+	var applied []*stagedFile
+
+	rollback := func() {
+		for i := len(removedDirs) - 1; i >= 0; i-- {
+			_ = os.MkdirAll(removedDirs[i], os.ModePerm)
+		}
+		for _, tmp := range temps {
+			_ = os.Remove(tmp)
+		}
+		for i := len(applied) - 1; i >= 0; i-- {
+			f := applied[i]
+			if f.existed {
+				_ = writeAtomic(f.path, f.before)
+			} else {
+				_ = os.Remove(f.path)
+			}
+		}
+		for i := len(createdDirs) - 1; i >= 0; i-- {
+			_ = os.Remove(createdDirs[i])
+		}
+	}
+
+	for _, dir := range c.dirs {
+		if err := os.Mkdir(dir, os.ModePerm); err != nil {
+			if info, statErr := os.Stat(dir); statErr == nil && info.IsDir() {
+				continue
+			}
+			rollback()
+			return err
+		}
+		createdDirs = append(createdDirs, dir)
+	}
+
+	for _, path := range c.order {
+		f := c.files[path]
+		if !f.changed() || f.deleted {
+			continue
+		}
+		tmp, err := writeTemp(path, f.after)
+		if err != nil {
+			rollback()
+			return err
+		}
+		temps[path] = tmp
+	}
+
+	for _, path := range c.order {
+		f := c.files[path]
+		if !f.changed() {
+			continue
+		}
+		var err error
+		if f.deleted {
+			err = os.Remove(path)
+		} else {
+			err = os.Rename(temps[path], path)
+		}
+		if err != nil {
+			rollback()
+			return err
+		}
+		delete(temps, path)
+		applied = append(applied, f)
+	}
+
+	for _, dir := range c.rmDirs {
+		if err := os.Remove(dir); err != nil {
+			rollback()
+			return err
+		}
+		removedDirs = append(removedDirs, dir)
+	}
+	return nil
+}
+
+// writeTemp writes data to a new temp file in the directory of path, keeping
+// the mode of an existing file at path, and returns the temp file name.
+func writeTemp(path string, data []byte) (string, error) {
+	mode := os.FileMode(0644)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".farch-*")
+	if err != nil {
+		return "", err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return "", err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return "", err
+	}
+	if err := os.Chmod(tmp.Name(), mode); err != nil {
+		os.Remove(tmp.Name())
+		return "", err
+	}
+	return tmp.Name(), nil
+}
+
+// writeAtomic replaces the file at path with data via a temp file and rename.
+func writeAtomic(path string, data []byte) error {
+	tmp, err := writeTemp(path, data)
+	if err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
+
+// printRollback lists the staged changes that were discarded or undone.
+func (c *changeSet) printRollback() {
+	paths := c.pending()
+	if len(paths) == 0 {
+		return
+	}
+	fmt.Printf("↩️  Rolled back %d change(s):\n", len(paths))
+	for _, path := range paths {
+		fmt.Printf("   %s\n", filepath.ToSlash(path))
+	}
+}
+
+// printPlan writes the staged changes as a tree followed by unified diffs for
+// every existing file that would be edited or deleted.
+func (c *changeSet) printPlan() {
+	marks := map[string]string{}
+	dirs := map[string]bool{}
+	for _, dir := range c.dirs {
+		marks[dir] = "+"
+		dirs[dir] = true
+	}
+	for _, dir := range c.rmDirs {
+		marks[dir] = "-"
+		dirs[dir] = true
+	}
+	for _, path := range c.skipped {
+		marks[path] = "="
+	}
+	for _, path := range c.order {
+		f := c.files[path]
+		switch {
+		case !f.changed():
+			continue
+		case f.deleted:
+			marks[path] = "-"
+		case f.existed:
+			marks[path] = "~"
+		default:
+			marks[path] = "+"
+		}
+	}
+
+	fmt.Println("🔍 Dry run: no files were written.")
+	if len(marks) == 0 {
+		fmt.Println("   (no changes)")
+		return
+	}
+	fmt.Println("   + create  ~ edit  - delete  = exists (skipped)")
+	printTree(marks, dirs)
+
+	for _, path := range c.order {
+		f := c.files[path]
+		if !f.existed || !f.changed() {
+			continue
+		}
+		fmt.Println()
+		fmt.Print(unifiedDiff(filepath.ToSlash(path), string(f.before), string(f.after)))
+	}
+}
+
+// printTree prints marked paths as an indented directory tree. Intermediate
+// directories without a mark are printed as context.
+func printTree(marks map[string]string, dirs map[string]bool) {
+	nodes := map[string]bool{}
+	for path := range marks {
+		for p := path; p != "." && p != string(filepath.Separator); p = filepath.Dir(p) {
+			nodes[p] = true
+		}
+	}
+	paths := make([]string, 0, len(nodes))
+	for p := range nodes {
+		paths = append(paths, p)
+	}
+	sort.Slice(paths, func(i, j int) bool {
+		return filepath.ToSlash(paths[i]) < filepath.ToSlash(paths[j])
+	})
+
+	for _, p := range paths {
+		depth := strings.Count(filepath.ToSlash(p), "/")
+		mark := marks[p]
+		if mark == "" {
+			mark = " "
+		}
+		name := filepath.Base(p)
+		if dirs[p] || isTreeDir(p, paths) {
+			name += "/"
+		}
+		fmt.Printf("%s %s%s\n", mark, strings.Repeat("  ", depth), name)
+	}
+}
+
+func isTreeDir(p string, paths []string) bool {
+	prefix := p + string(filepath.Separator)
+	for _, other := range paths {
+		if strings.HasPrefix(other, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDryRunWritesNothingAndPrintsPlan(t *testing.T) {
+	withTempDir(t)
+	_ = runMain(t, "new", "page", "orders", "details")
+	routerBefore := mustReadFile(t, filepath.Join("lib", "core", "router.dart"))
+
+	out := runMain(t, "--dry-run", "new", "page", "orders", "summary")
+
+	if _, err := os.Stat(filepath.Join("lib", "features", "orders", "presentation", "pages", "summary_page.dart")); !os.IsNotExist(err) {
+		t.Fatalf("dry run must not create files")
+	}
+	if got := mustReadFile(t, filepath.Join("lib", "core", "router.dart")); got != routerBefore {
+		t.Fatalf("dry run must not edit router.dart, got:\n%s", got)
+	}
+
+	for _, expect := range []string{
+		"Dry run",
+		"+           summary_page.dart",
+		"~     router.dart",
+		"--- a/lib/core/router.dart",
+		"+import '../features/orders/presentation/pages/summary_page.dart';",
+		"+const kSummaryPage = '/summary';",
+	} {
+		if !strings.Contains(out, expect) {
+			t.Fatalf("expected dry run output to contain %q, got:\n%s", expect, out)
+		}
+	}
+	if strings.Contains(out, "Created") {
+		t.Fatalf("expected only the plan to describe created files, got:\n%s", out)
+	}
+}
+
+func TestDryRunReportsSkippedFiles(t *testing.T) {
+	withTempDir(t)
+	_ = runMain(t, "new", "entity", "orders", "invoice")
+
+	out := runMain(t, "new", "entity", "orders", "invoice", "--dry-run")
+	if !strings.Contains(out, "=           invoice.dart") {
+		t.Fatalf("expected skipped entity in plan, got:\n%s", out)
+	}
+}
+
+func TestChangeSetReadsStagedWrites(t *testing.T) {
+	withTempDir(t)
+
+	c := newChangeSet()
+	if err := c.writeFile(filepath.Join("a", "b.txt"), []byte("one")); err != nil {
+		t.Fatalf("writeFile failed: %v", err)
+	}
+	if !c.exists(filepath.Join("a", "b.txt")) || !c.exists("a") {
+		t.Fatalf("staged file and parent dir should exist")
+	}
+	if data, _ := c.readFile(filepath.Join("a", "b.txt")); string(data) != "one" {
+		t.Fatalf("expected staged content, got %q", data)
+	}
+	if _, err := os.Stat("a"); !os.IsNotExist(err) {
+		t.Fatalf("nothing should be on disk before commit")
+	}
+	if err := c.commit(); err != nil {
+		t.Fatalf("commit failed: %v", err)
+	}
+	if got := mustReadFile(t, filepath.Join("a", "b.txt")); got != "one" {
+		t.Fatalf("expected committed content, got %q", got)
+	}
+}
+
+func TestFailedStepRollsBackWholeCommand(t *testing.T) {
+	withTempDir(t)
+
+	// a directory where router.dart should be makes appendRoute fail
+	if err := os.MkdirAll(filepath.Join("lib", "core", "router.dart"), 0755); err != nil {
+		t.Fatalf("mkdir failed: %v", err)
+	}
+
+	out, code := runMainCode(t, "new", "page", "orders", "details")
+	if code == 0 {
+		t.Fatalf("expected non-zero exit code, got output:\n%s", out)
+	}
+	if !strings.Contains(out, "Rolled back") || !strings.Contains(out, "details_page.dart") {
+		t.Fatalf("expected rollback listing, got:\n%s", out)
+	}
+	if _, err := os.Stat(filepath.Join("lib", "features")); !os.IsNotExist(err) {
+		t.Fatalf("no feature files should be written after a failed step")
+	}
+	if _, err := os.Stat(filepath.Join("lib", "core", "page_names.dart")); !os.IsNotExist(err) {
+		t.Fatalf("page_names.dart should not be written after a failed step")
+	}
+}
+
+func TestCommitRestoresAppliedFilesOnFailure(t *testing.T) {
+	withTempDir(t)
+
+	if err := os.WriteFile("a.txt", []byte("original"), 0644); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+
+	c := newChangeSet()
+	_ = c.writeFile("a.txt", []byte("changed"))
+	_ = c.writeFile(filepath.Join("z", "c.txt"), []byte("new"))
+
+	// make the final rename fail by putting a directory at the target
+	if err := os.MkdirAll(filepath.Join("z", "c.txt"), 0755); err != nil {
+		t.Fatalf("mkdir failed: %v", err)
+	}
+
+	if err := c.commit(); err == nil {
+		t.Fatalf("expected commit to fail")
+	}
+	if got := mustReadFile(t, "a.txt"); got != "original" {
+		t.Fatalf("a.txt should be restored, got %q", got)
+	}
+	entries, _ := os.ReadDir(".")
+	for _, e := range entries {
+		if strings.Contains(e.Name(), ".farch-") {
+			t.Fatalf("temp file left behind: %s", e.Name())
+		}
+	}
+}
@@ -0,0 +1,203 @@
+// config.go
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// configFileNames are looked up, in order, at the Flutter project root.
+var configFileNames = []string{".farch.yaml", ".farch.yml", "farch.json"}
+
+// Config holds the project-level settings that can be overridden from
+// .farch.yaml / farch.json. Keys missing from the file keep the defaults.
+type Config struct {
+	Structure []string   `yaml:"structure" json:"structure"`
+	Suffixes  Suffixes   `yaml:"suffixes" json:"suffixes"`
+	Scaffolds []Scaffold `yaml:"scaffolds" json:"scaffolds"`
+	Paths     Paths      `yaml:"paths" json:"paths"`
+	// DI selects the injection container flavour: "get_it" or "riverpod".
+	DI string `yaml:"di" json:"di"`
+	// StateManagement selects what pages build on: "riverpod" providers or
+	// "bloc" cubits and blocs.
+	StateManagement string `yaml:"state_management" json:"state_management"`
+	// RouteNames selects how page route constants and paths are named: after
+	// the "page" alone or prefixed with its "feature".
+	RouteNames string `yaml:"route_names" json:"route_names"`
+	// Lint holds the layer rules checked by farch lint.
+	Lint LintConfig `yaml:"lint" json:"lint"`
+}
+
+// Suffixes are appended to the generated name before ".dart".
+type Suffixes struct {
+	Entity         string `yaml:"entity" json:"entity"`
+	Usecase        string `yaml:"usecase" json:"usecase"`
+	Repository     string `yaml:"repository" json:"repository"`
+	RepositoryImpl string `yaml:"repository_impl" json:"repository_impl"`
+	Datasource     string `yaml:"datasource" json:"datasource"`
+	Model          string `yaml:"model" json:"model"`
+	Provider       string `yaml:"provider" json:"provider"`
+	Page           string `yaml:"page" json:"page"`
+	Widget         string `yaml:"widget" json:"widget"`
+}
+
+// Scaffold is one default file emitted by createFeature. Name may contain
+// %s, which is replaced by the feature name.
+type Scaffold struct {
+	Kind string `yaml:"kind" json:"kind"`
+	Name string `yaml:"name" json:"name"`
+}
+
+// Paths are the locations of the shared project files farch edits.
+type Paths struct {
+	Router             string `yaml:"router" json:"router"`
+	PageNames          string `yaml:"page_names" json:"page_names"`
+	InjectionContainer string `yaml:"injection_container" json:"injection_container"`
+}
+
+// cfg is the active configuration; main replaces it with loadConfig's result.
+var cfg = defaultConfig()
+
+func defaultConfig() Config {
+	return Config{
+		Structure: append([]string(nil), baseStructure...),
+		Suffixes: Suffixes{
+			Entity:         "",
+			Usecase:        "",
+			Repository:     "_repository",
+			RepositoryImpl: "_repository_impl",
+			Datasource:     "_datasource",
+			Model:          "_model",
+			Provider:       "_provider",
+			Page:           "_page",
+			Widget:         "_widget",
+		},
+		Scaffolds: []Scaffold{
+			{Kind: "entity", Name: "%s"},
+			{Kind: "usecase", Name: "example"},
+			{Kind: "repository", Name: "%s"},
+			{Kind: "datasource", Name: "remote"},
+			{Kind: "provider", Name: "%s"},
+			{Kind: "page", Name: "%s"},
+		},
+		Paths: Paths{
+			Router:             filepath.Join("lib", "core", "router.dart"),
+			PageNames:          filepath.Join("lib", "core", "page_names.dart"),
+			InjectionContainer: filepath.Join("lib", "injection_container.dart"),
+		},
+		DI:              diGetIt,
+		StateManagement: stateRiverpod,
+		RouteNames:      routeNamesPage,
+		Lint:            defaultLintConfig(),
+	}
+}
+
+// loadConfig reads the project config from dir. It returns the defaults when
+// no config file exists.
+func loadConfig(dir string) (Config, string, error) {
+	c := defaultConfig()
+
+	found := ""
+	for _, name := range configFileNames {
+		path := filepath.Join(dir, name)
+		if _, err := os.Stat(path); err == nil {
+			if found != "" {
+				return c, "", fmt.Errorf("both %s and %s exist; keep only one", found, name)
+			}
+			found = name
+		}
+	}
+	if found == "" {
+		return c, "", nil
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, found))
+	if err != nil {
+		return c, found, fmt.Errorf("failed to read %s: %w", found, err)
+	}
+	if err := decodeConfig(found, data, &c); err != nil {
+		return c, found, fmt.Errorf("%s: %w", found, err)
+	}
+	if err := c.validate(); err != nil {
+		return c, found, fmt.Errorf("%s: %w", found, err)
+	}
+	return c, found, nil
+}
+
+// decodeConfig decodes data over c so that absent keys keep their current
+// values. Unknown keys are rejected.
+func decodeConfig(name string, data []byte, c *Config) error {
+	if strings.HasSuffix(name, ".json") {
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(c); err != nil {
+			return err
+		}
+		return nil
+	}
+
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(c); err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+	return nil
+}
+
+var scaffoldKinds = map[string]bool{
+	"entity":     true,
+	"usecase":    true,
+	"repository": true,
+	"datasource": true,
+	"model":      true,
+	"provider":   true,
+	"page":       true,
+}
+
+func (c Config) validate() error {
+	if len(c.Structure) == 0 {
+		return fmt.Errorf("structure must list at least one directory")
+	}
+	for _, s := range c.Scaffolds {
+		if !scaffoldKinds[s.Kind] {
+			return fmt.Errorf("unknown scaffold kind %q (use entity | usecase | repository | datasource | model | provider | page)", s.Kind)
+		}
+		if strings.TrimSpace(s.Name) == "" {
+			return fmt.Errorf("scaffold of kind %q is missing a name", s.Kind)
+		}
+	}
+	if c.Paths.Router == "" || c.Paths.PageNames == "" || c.Paths.InjectionContainer == "" {
+		return fmt.Errorf("paths.router, paths.page_names and paths.injection_container must not be empty")
+	}
+	if c.DI != diGetIt && c.DI != diRiverpod {
+		return fmt.Errorf("unknown di %q (use get_it | riverpod)", c.DI)
+	}
+	if c.StateManagement != stateRiverpod && c.StateManagement != stateBloc {
+		return fmt.Errorf("unknown state_management %q (use riverpod | bloc)", c.StateManagement)
+	}
+	if c.RouteNames != routeNamesPage && c.RouteNames != routeNamesFeature {
+		return fmt.Errorf("unknown route_names %q (use page | feature)", c.RouteNames)
+	}
+	if err := c.Lint.validate(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// dartRelImport returns the relative import path from the Dart file at from
+// to the Dart file at to, using forward slashes.
+func dartRelImport(from, to string) string {
+	rel, err := filepath.Rel(filepath.Dir(from), to)
+	if err != nil {
+		return filepath.ToSlash(to)
+	}
+	return filepath.ToSlash(rel)
+}
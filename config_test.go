@@ -0,0 +1,125 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadConfigDefaultsWithoutFile(t *testing.T) {
+	withTempDir(t)
+
+	c, name, err := loadConfig(".")
+	if err != nil {
+		t.Fatalf("loadConfig failed: %v", err)
+	}
+	if name != "" {
+		t.Fatalf("expected no config file, got %q", name)
+	}
+	if len(c.Structure) != len(baseStructure) {
+		t.Fatalf("expected default structure, got %v", c.Structure)
+	}
+	if c.Paths.Router != filepath.Join("lib", "core", "router.dart") {
+		t.Fatalf("unexpected default router path %q", c.Paths.Router)
+	}
+}
+
+func TestLoadConfigYAMLOverridesAndFallsBack(t *testing.T) {
+	withTempDir(t)
+
+	yaml := `structure:
+  - lib/features/%s/domain
+suffixes:
+  page: _screen
+paths:
+  router: lib/app/app_router.dart
+`
+	if err := os.WriteFile(".farch.yaml", []byte(yaml), 0644); err != nil {
+		t.Fatalf("write config failed: %v", err)
+	}
+
+	c, name, err := loadConfig(".")
+	if err != nil {
+		t.Fatalf("loadConfig failed: %v", err)
+	}
+	if name != ".farch.yaml" {
+		t.Fatalf("expected .farch.yaml, got %q", name)
+	}
+	if len(c.Structure) != 1 || c.Structure[0] != "lib/features/%s/domain" {
+		t.Fatalf("structure not overridden: %v", c.Structure)
+	}
+	if c.Suffixes.Page != "_screen" || c.Suffixes.Provider != "_provider" {
+		t.Fatalf("unexpected suffixes: %+v", c.Suffixes)
+	}
+	if c.Paths.Router != "lib/app/app_router.dart" || c.Paths.PageNames != filepath.Join("lib", "core", "page_names.dart") {
+		t.Fatalf("unexpected paths: %+v", c.Paths)
+	}
+	if len(c.Scaffolds) != 6 {
+		t.Fatalf("expected default scaffolds, got %v", c.Scaffolds)
+	}
+}
+
+func TestLoadConfigRejectsUnknownKeys(t *testing.T) {
+	tests := []struct {
+		file    string
+		content string
+		expect  string
+	}{
+		{file: ".farch.yaml", content: "structur:\n  - lib\n", expect: "structur"},
+		{file: "farch.json", content: `{"paths": {"routes": "x.dart"}}`, expect: "routes"},
+		{file: "farch.json", content: `{"scaffolds": [{"kind": "widget", "name": "x"}]}`, expect: "unknown scaffold kind"},
+		{file: ".farch.yaml", content: "di: provider\n", expect: "unknown di"},
+		{file: ".farch.yaml", content: "state_management: mobx\n", expect: "unknown state_management"},
+		{file: ".farch.yaml", content: "route_names: module\n", expect: "unknown route_names"},
+		{file: ".farch.yaml", content: "lint:\n  layers:\n    ui: [domain]\n", expect: "unknown lint layer \"ui\""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.file+" "+tt.expect, func(t *testing.T) {
+			withTempDir(t)
+			if err := os.WriteFile(tt.file, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("write config failed: %v", err)
+			}
+			_, _, err := loadConfig(".")
+			if err == nil || !strings.Contains(err.Error(), tt.expect) {
+				t.Fatalf("expected error containing %q, got %v", tt.expect, err)
+			}
+		})
+	}
+}
+
+func TestNewFeatureHonorsConfig(t *testing.T) {
+	withTempDir(t)
+
+	json := `{
+  "structure": ["lib/features/%s/domain/entities"],
+  "suffixes": {"page": "_screen"},
+  "scaffolds": [{"kind": "page", "name": "%s_home"}],
+  "paths": {
+    "router": "lib/app/app_router.dart",
+    "page_names": "lib/app/routes.dart",
+    "injection_container": "lib/di.dart"
+  }
+}`
+	if err := os.WriteFile("farch.json", []byte(json), 0644); err != nil {
+		t.Fatalf("write config failed: %v", err)
+	}
+
+	_ = runMain(t, "new", "feature", "orders")
+
+	mustExist(t, filepath.Join("lib", "features", "orders", "presentation", "pages", "orders_home_screen.dart"))
+	mustExist(t, filepath.Join("lib", "app", "routes.dart"))
+	mustExist(t, filepath.Join("lib", "di.dart"))
+	if _, err := os.Stat(filepath.Join("lib", "features", "orders", "domain", "usecases", "example.dart")); !os.IsNotExist(err) {
+		t.Fatalf("default usecase scaffold should not be generated")
+	}
+
+	router := mustReadFile(t, filepath.Join("lib", "app", "app_router.dart"))
+	if !strings.Contains(router, "import '../features/orders/presentation/pages/orders_home_screen.dart';") {
+		t.Fatalf("router should import page relative to its location, got:\n%s", router)
+	}
+	if !strings.Contains(router, "import 'routes.dart';") {
+		t.Fatalf("router should import configured page names file, got:\n%s", router)
+	}
+}
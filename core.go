@@ -0,0 +1,41 @@
+// core.go
+package main
+
+import "path/filepath"
+
+// Core building blocks shared by every feature. They are written on first
+// use and left alone afterwards, so projects may extend them.
+var (
+	coreFailuresFile   = filepath.Join("lib", "core", "error", "failures.dart")
+	coreExceptionsFile = filepath.Join("lib", "core", "error", "exceptions.dart")
+	coreUsecaseFile    = filepath.Join("lib", "core", "usecases", "usecase.dart")
+	coreResultFile     = filepath.Join("lib", "core", "utils", "result.dart")
+)
+
+// coreTemplates maps each core file to the template that renders it.
+var coreTemplates = []struct{ file, template string }{
+	{coreFailuresFile, "core_failures.dart.tmpl"},
+	{coreExceptionsFile, "core_exceptions.dart.tmpl"},
+	{coreUsecaseFile, "core_usecase.dart.tmpl"},
+	{coreResultFile, "core_result.dart.tmpl"},
+}
+
+// ensureCore writes the core files that do not exist yet.
+func ensureCore() {
+	for _, c := range coreTemplates {
+		if changes.exists(c.file) {
+			continue
+		}
+		changes.mkdirAll(filepath.Dir(c.file))
+		writeTemplate(c.file, c.template, templateData{})
+	}
+}
+
+// coreImports returns the imports of the given core files relative to file.
+func coreImports(file string, core ...string) []string {
+	imports := make([]string, len(core))
+	for i, c := range core {
+		imports[i] = dartRelImport(file, c)
+	}
+	return imports
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCoreFilesGeneratedOnFirstUse(t *testing.T) {
+	withTempDir(t)
+	_ = runMain(t, "new", "page", "orders", "orders")
+	mustNotExist(t, coreResultFile)
+
+	_ = runMain(t, "new", "feature", "orders")
+	for _, c := range coreTemplates {
+		mustExist(t, c.file)
+	}
+	if failures := mustReadFile(t, coreFailuresFile); !strings.Contains(failures, "sealed class Failure implements Exception {") {
+		t.Fatalf("unexpected failures file:\n%s", failures)
+	}
+	if usecase := mustReadFile(t, coreUsecaseFile); !strings.Contains(usecase, "abstract class UseCase<Type, Params> {") || !strings.Contains(usecase, "class NoParams {") {
+		t.Fatalf("unexpected usecase base file:\n%s", usecase)
+	}
+
+	usecase := mustReadFile(t, filepath.Join("lib", "features", "orders", "domain", "usecases", "example.dart"))
+	for _, expect := range []string{
+		"import '../../../../core/usecases/usecase.dart';\nimport '../../../../core/utils/result.dart';\n\n",
+		"class Example extends UseCase<void, NoParams> {",
+		"  Future<Result<void>> call(NoParams params) async {",
+	} {
+		if !strings.Contains(usecase, expect) {
+			t.Fatalf("expected usecase to contain %q, got:\n%s", expect, usecase)
+		}
+	}
+
+	// customised core files are kept
+	if err := os.WriteFile(coreResultFile, []byte("// custom\n"), 0644); err != nil {
+		t.Fatalf("write result failed: %v", err)
+	}
+	out := runMain(t, "new", "usecase", "orders", "sync")
+	if strings.Contains(out, coreResultFile) {
+		t.Fatalf("expected existing core files to be left alone, got:\n%s", out)
+	}
+	if got := mustReadFile(t, coreResultFile); got != "// custom\n" {
+		t.Fatalf("core file was overwritten:\n%s", got)
+	}
+}
@@ -0,0 +1,310 @@
+// di.go
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// DI container flavours selectable with the "di" config key.
+const (
+	diGetIt    = "get_it"
+	diRiverpod = "riverpod"
+)
+
+// legacyContainer is the placeholder older versions wrote; it is replaced by
+// a real container on first use.
+const legacyContainer = "// Dependency injection setup\n"
+
+// Section markers of the injection container. Registrations are inserted
+// above them, like GoRoutes above // AUTO_ROUTES in router.dart.
+const (
+	diExternalMarker     = "// AUTO_EXTERNAL"
+	diDatasourcesMarker  = "// AUTO_DATASOURCES"
+	diRepositoriesMarker = "// AUTO_REPOSITORIES"
+	diUsecasesMarker     = "// AUTO_USECASES"
+)
+
+const getItContainer = `import 'package:get_it/get_it.dart';
+// REQUIRED_FOR_FARCH: Do not remove this marker. farch inserts imports above this line.
+// AUTO_IMPORTS
+
+final sl = GetIt.instance;
+
+Future<void> init() async {
+  // External
+  // REQUIRED_FOR_FARCH: Do not remove this marker. farch inserts registrations above this line.
+  // AUTO_EXTERNAL
+
+  // Datasources
+  // REQUIRED_FOR_FARCH: Do not remove this marker. farch inserts registrations above this line.
+  // AUTO_DATASOURCES
+
+  // Repositories
+  // REQUIRED_FOR_FARCH: Do not remove this marker. farch inserts registrations above this line.
+  // AUTO_REPOSITORIES
+
+  // Usecases
+  // REQUIRED_FOR_FARCH: Do not remove this marker. farch inserts registrations above this line.
+  // AUTO_USECASES
+}
+`
+
+const riverpodContainer = `import 'package:flutter_riverpod/flutter_riverpod.dart';
+// REQUIRED_FOR_FARCH: Do not remove this marker. farch inserts imports above this line.
+// AUTO_IMPORTS
+
+// External
+// REQUIRED_FOR_FARCH: Do not remove this marker. farch inserts providers above this line.
+// AUTO_EXTERNAL
+
+// Datasources
+// REQUIRED_FOR_FARCH: Do not remove this marker. farch inserts providers above this line.
+// AUTO_DATASOURCES
+
+// Repositories
+// REQUIRED_FOR_FARCH: Do not remove this marker. farch inserts providers above this line.
+// AUTO_REPOSITORIES
+
+// Usecases
+// REQUIRED_FOR_FARCH: Do not remove this marker. farch inserts providers above this line.
+// AUTO_USECASES
+`
+
+// ensureInjectionContainer creates the injection container, or upgrades the
+// legacy placeholder, for the configured DI flavour.
+func ensureInjectionContainer() bool {
+	file := cfg.Paths.InjectionContainer
+	if changes.exists(file) {
+		data, err := changes.readFile(file)
+		if err != nil {
+			failf("Failed to read %s: %v", file, err)
+			return false
+		}
+		if string(data) != legacyContainer {
+			return true
+		}
+	}
+	base := getItContainer
+	if cfg.DI == diRiverpod {
+		base = riverpodContainer
+	}
+	changes.mkdirAll(filepath.Dir(file))
+	if err := changes.writeFile(file, []byte(base)); err != nil {
+		failf("Failed writing %s: %v", file, err)
+		return false
+	}
+	changes.createdf("📝 Created %s\n", file)
+	return true
+}
+
+// diPrefix is the import prefix of a feature's files in the container, so
+// features may reuse class names such as RemoteDataSource.
+func diPrefix(feature string) string {
+	return snakeCase(feature)
+}
+
+// diProviderName names the Riverpod provider of a feature's dependency,
+// dropping the name when it repeats the feature ("ordersRepositoryProvider").
+func diProviderName(feature, name, suffix string) string {
+	if snakeCase(name) == snakeCase(feature) {
+		return camelCase(snakeCase(feature)) + suffix
+	}
+	return camelCase(snakeCase(feature)) + pascalCase(snakeCase(name)) + suffix
+}
+
+// registerDatasource registers a datasource under its abstract type. Dio
+// datasources get the shared Dio instance.
+func registerDatasource(feature, dsName, dsFile string, usesDio bool) {
+	p := diPrefix(feature)
+	iface := p + "." + pascalCase(dsName) + "DataSource"
+	args := ""
+	if usesDio {
+		registerDio()
+		args = "sl()"
+		if cfg.DI == diRiverpod {
+			args = "ref.watch(dioProvider)"
+		}
+	}
+	line := fmt.Sprintf("  sl.registerLazySingleton<%s>(() => %sImpl(%s));", iface, iface, args)
+	if cfg.DI == diRiverpod {
+		line = fmt.Sprintf("final %s = Provider<%s>((ref) => %sImpl(%s));", diProviderName(feature, dsName, "DataSourceProvider"), iface, iface, args)
+	}
+	addRegistration(diDatasourcesMarker, dsFile, p, line)
+}
+
+// registerRepository registers a repository implementation under its domain
+// interface. dsName is the datasource its constructor takes, if any.
+func registerRepository(feature, repoName, domainFile, dataFile, dsName string) {
+	p := diPrefix(feature)
+	iface := p + "." + pascalCase(repoName) + "Repository"
+	args := ""
+	if dsName != "" {
+		args = "sl()"
+		if cfg.DI == diRiverpod {
+			args = "ref.watch(" + diProviderName(feature, dsName, "DataSourceProvider") + ")"
+		}
+	}
+	line := fmt.Sprintf("  sl.registerLazySingleton<%s>(() => %sImpl(%s));", iface, iface, args)
+	if cfg.DI == diRiverpod {
+		line = fmt.Sprintf("final %s = Provider<%s>((ref) => %sImpl(%s));", diProviderName(feature, repoName, "RepositoryProvider"), iface, iface, args)
+	}
+	addRegistration(diRepositoriesMarker, domainFile, p, "")
+	addRegistration(diRepositoriesMarker, dataFile, p, line)
+}
+
+// registerUsecase registers a usecase. repoName is the repository its
+// constructor takes, if any.
+func registerUsecase(feature, usecaseName, file, repoName string) {
+	p := diPrefix(feature)
+	class := p + "." + pascalCase(usecaseName)
+	args := ""
+	if repoName != "" {
+		args = "sl()"
+		if cfg.DI == diRiverpod {
+			args = "ref.watch(" + diProviderName(feature, repoName, "RepositoryProvider") + ")"
+		}
+	}
+	line := fmt.Sprintf("  sl.registerLazySingleton(() => %s(%s));", class, args)
+	if cfg.DI == diRiverpod {
+		line = fmt.Sprintf("final %s = Provider<%s>((ref) => %s(%s));", diProviderName(feature, usecaseName, "UsecaseProvider"), class, class, args)
+	}
+	addRegistration(diUsecasesMarker, file, p, line)
+}
+
+// registerDio registers the Dio client that generated API datasources use.
+func registerDio() {
+	line := "  sl.registerLazySingleton<Dio>(() => Dio());"
+	if cfg.DI == diRiverpod {
+		line = "final dioProvider = Provider<Dio>((ref) => Dio());"
+	}
+	addRegistration(diExternalMarker, "", "", line)
+	addContainerImport("import 'package:dio/dio.dart';")
+}
+
+func addContainerImport(importLine string) {
+	file := cfg.Paths.InjectionContainer
+	data, err := changes.readFile(file)
+	if err != nil {
+		failf("Failed to read %s: %v", file, err)
+		return
+	}
+	if hasDirective(string(data), importLine) {
+		return
+	}
+	if err := changes.writeFile(file, []byte(insertImportDirective(string(data), importLine))); err != nil {
+		failf("Failed to update %s: %v", file, err)
+	}
+}
+
+// addRegistration imports target (with the feature prefix) into the
+// container and inserts line above the section marker, unless present.
+func addRegistration(marker, target, prefix, line string) {
+	if !ensureInjectionContainer() {
+		return
+	}
+	file := cfg.Paths.InjectionContainer
+	if target != "" {
+		addContainerImport(fmt.Sprintf("import '%s' as %s;", dartRelImport(file, target), prefix))
+	}
+	if line == "" {
+		return
+	}
+	data, err := changes.readFile(file)
+	if err != nil {
+		failf("Failed to read %s: %v", file, err)
+		return
+	}
+	content := string(data)
+	if strings.Contains(content, line) {
+		return
+	}
+	idx := strings.Index(content, marker)
+	if idx == -1 {
+		fmt.Printf("⚠️  %s is missing the %s marker; skipped registration:\n   %s\n", filepath.Base(file), marker, strings.TrimSpace(line))
+		return
+	}
+	lineStart := strings.LastIndex(content[:idx], "\n") + 1
+	indent := content[lineStart:idx]
+	content = content[:lineStart] + indent + strings.TrimSpace(line) + "\n" + content[lineStart:]
+	if err := changes.writeFile(file, []byte(content)); err != nil {
+		failf("Failed to update %s: %v", file, err)
+		return
+	}
+	fmt.Printf("💉 Registered %s in %s\n", diRegisteredName(line), filepath.Base(file))
+}
+
+// diRegisteredNameRe finds the class a registration line constructs.
+var diRegisteredNameRe = regexp.MustCompile(`=> ([A-Za-z_][A-Za-z0-9_.]*)\(`)
+
+func diRegisteredName(line string) string {
+	if m := diRegisteredNameRe.FindStringSubmatch(line); m != nil {
+		return m[1]
+	}
+	return strings.TrimSpace(line)
+}
+
+// unregisterFeature drops a feature's imports and registrations from the
+// injection container.
+func unregisterFeature(feature string) {
+	file := cfg.Paths.InjectionContainer
+	if !changes.exists(file) {
+		return
+	}
+	prefix := diPrefix(feature)
+	uses := regexp.MustCompile(`\b` + regexp.QuoteMeta(prefix) + `\.[A-Z]`)
+	removed := editDirectives(file, func(d dartDirective) bool {
+		return d.Keyword == dirImport && d.Prefix == prefix
+	})
+	removed += editLines(file, uses.MatchString)
+	if removed > 0 {
+		fmt.Printf("➖ Removed %d line(s) for feature %s from %s\n", removed, feature, filepath.Base(file))
+	}
+}
+
+// renameFeatureRegistrations renames a feature's prefix, classes and
+// providers in the injection container. Import URIs are repointed with the
+// moved files.
+func renameFeatureRegistrations(oldName, newName string) {
+	file := cfg.Paths.InjectionContainer
+	if !changes.exists(file) {
+		return
+	}
+	data, err := changes.readFile(file)
+	if err != nil {
+		failf("Failed to read %s: %v", file, err)
+		return
+	}
+	prefix := diPrefix(oldName)
+	uses := regexp.MustCompile(`\b` + regexp.QuoteMeta(prefix) + `\.[A-Z]`)
+	directives := parseDirectives(string(data))
+	inDirective := directiveLines(directives)
+	var aliased []dartDirective
+	for _, d := range directives {
+		if d.Keyword == dirImport && d.Prefix == prefix {
+			aliased = append(aliased, d)
+		}
+	}
+	imported := directiveLines(aliased)
+	lines := strings.Split(string(data), "\n")
+	changed := false
+	for i, line := range lines {
+		if imported[i] || (!inDirective[i] && uses.MatchString(line)) {
+			renamed := renameIdentifiers(line, oldName, newName)
+			if renamed != line {
+				lines[i] = renamed
+				changed = true
+			}
+		}
+	}
+	if !changed {
+		return
+	}
+	if err := changes.writeFile(file, []byte(strings.Join(lines, "\n"))); err != nil {
+		failf("Failed to update %s: %v", file, err)
+		return
+	}
+	fmt.Printf("🔗 Renamed feature %s to %s in %s\n", oldName, newName, filepath.Base(file))
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestNewFeatureRegistersDependencies(t *testing.T) {
+	withTempDir(t)
+	_ = runMain(t, "new", "feature", "orders")
+	_ = runMain(t, "new", "usecase", "orders", "get_order", "--repo", "orders", "--returns", "int")
+
+	container := mustReadFile(t, filepath.Join("lib", "injection_container.dart"))
+	for _, expect := range []string{
+		"import 'package:get_it/get_it.dart';",
+		"import 'features/orders/data/datasources/remote_datasource.dart' as orders;",
+		"import 'features/orders/domain/repositories/orders_repository.dart' as orders;",
+		"  sl.registerLazySingleton<orders.RemoteDataSource>(() => orders.RemoteDataSourceImpl());\n  // AUTO_DATASOURCES",
+		"  sl.registerLazySingleton<orders.OrdersRepository>(() => orders.OrdersRepositoryImpl());\n  // AUTO_REPOSITORIES",
+		"  sl.registerLazySingleton(() => orders.Example());\n  sl.registerLazySingleton(() => orders.GetOrder(sl()));\n  // AUTO_USECASES",
+	} {
+		if !strings.Contains(container, expect) {
+			t.Fatalf("expected container to contain %q, got:\n%s", expect, container)
+		}
+	}
+
+	_ = runMain(t, "rename", "feature", "orders", "sales")
+	container = mustReadFile(t, filepath.Join("lib", "injection_container.dart"))
+	for _, expect := range []string{
+		"import 'features/sales/domain/repositories/sales_repository.dart' as sales;",
+		"  sl.registerLazySingleton<sales.SalesRepository>(() => sales.SalesRepositoryImpl());",
+	} {
+		if !strings.Contains(container, expect) {
+			t.Fatalf("expected renamed container to contain %q, got:\n%s", expect, container)
+		}
+	}
+
+	_ = runMain(t, "remove", "feature", "sales", "--force")
+	container = mustReadFile(t, filepath.Join("lib", "injection_container.dart"))
+	if strings.Contains(container, "sales") {
+		t.Fatalf("expected feature registrations to be removed, got:\n%s", container)
+	}
+	if container != getItContainer {
+		t.Fatalf("expected the empty container to remain, got:\n%s", container)
+	}
+}
+
+func TestRiverpodInjectionContainer(t *testing.T) {
+	withTempDir(t)
+	if err := os.WriteFile(".farch.yaml", []byte("di: riverpod\n"), 0644); err != nil {
+		t.Fatalf("write config failed: %v", err)
+	}
+	if err := os.MkdirAll("lib", 0755); err != nil {
+		t.Fatalf("mkdir failed: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join("lib", "injection_container.dart"), []byte(legacyContainer), 0644); err != nil {
+		t.Fatalf("write container failed: %v", err)
+	}
+	_ = runMain(t, "new", "repository", "orders", "orders")
+	_ = runMain(t, "new", "usecase", "orders", "get_order", "--repo", "orders")
+
+	container := mustReadFile(t, filepath.Join("lib", "injection_container.dart"))
+	for _, expect := range []string{
+		"import 'package:flutter_riverpod/flutter_riverpod.dart';",
+		"final ordersRepositoryProvider = Provider<orders.OrdersRepository>((ref) => orders.OrdersRepositoryImpl());",
+		"final ordersGetOrderUsecaseProvider = Provider<orders.GetOrder>((ref) => orders.GetOrder(ref.watch(ordersRepositoryProvider)));",
+	} {
+		if !strings.Contains(container, expect) {
+			t.Fatalf("expected container to contain %q, got:\n%s", expect, container)
+		}
+	}
+	if strings.Contains(container, "Dependency injection setup") {
+		t.Fatalf("expected the legacy placeholder to be replaced, got:\n%s", container)
+	}
+}
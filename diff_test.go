@@ -0,0 +1,32 @@
+package main
+
+import "testing"
+
+func TestUnifiedDiff(t *testing.T) {
+	a := "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n"
+	b := "1\n2\nthree\n4\n5\n6\n7\n8\n9\n10\n11\n12\n13\n"
+
+	got := unifiedDiff("f.dart", a, b)
+	expect := `--- a/f.dart
++++ b/f.dart
+@@ -1,6 +1,6 @@
+ 1
+ 2
+-3
++three
+ 4
+ 5
+ 6
+@@ -10,3 +10,4 @@
+ 10
+ 11
+ 12
++13
+`
+	if got != expect {
+		t.Fatalf("unexpected diff:\n%s\nwant:\n%s", got, expect)
+	}
+	if unifiedDiff("f.dart", a, a) != "" {
+		t.Fatalf("equal inputs should produce no diff")
+	}
+}
@@ -0,0 +1,590 @@
+// directives.go
+package main
+
+import (
+	"sort"
+	"strings"
+)
+
+// Directive keywords. A "part of" directive names the library a part belongs
+// to and is told apart from "part".
+const (
+	dirImport  = "import"
+	dirExport  = "export"
+	dirPart    = "part"
+	dirPartOf  = "part of"
+	dirLibrary = "library"
+)
+
+// dartURI is a string literal of a directive. Start and End are the byte
+// offsets of its value, inside the quotes.
+type dartURI struct {
+	Value      string
+	Start, End int
+}
+
+// dartDirective is a directive at the top of a Dart file. URIs holds the URI
+// first, then those of conditional imports ("if (dart.library.io) '…'").
+// Start and End are the byte offsets from the keyword through the ';', and
+// Line and EndLine the 1-based lines they fall on.
+type dartDirective struct {
+	Keyword       string
+	URIs          []dartURI
+	Prefix        string
+	Start, End    int
+	Line, EndLine int
+}
+
+// URI returns the main URI of the directive, or "" for a library directive
+// or a "part of" naming its library.
+func (d dartDirective) URI() string {
+	if len(d.URIs) == 0 {
+		return ""
+	}
+	return d.URIs[0].Value
+}
+
+// dartToken is a token of the directive section: an identifier, a string
+// literal (Text is its value) or a single punctuation character.
+type dartToken struct {
+	Kind       byte // 'i' identifier, 's' string, 'p' punctuation, 0 at the end
+	Text       string
+	Start, End int
+	ValueStart int // start of the value of a string, after the quotes
+}
+
+// dartLexer tokenizes Dart source, skipping whitespace and comments.
+type dartLexer struct {
+	src string
+	pos int
+}
+
+func isIdentByte(c byte) bool {
+	return c == '_' || c == '$' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+// skipSpace skips whitespace, line comments and (nested) block comments.
+func (l *dartLexer) skipSpace() {
+	for l.pos < len(l.src) {
+		switch {
+		case strings.ContainsRune(" \t\r\n", rune(l.src[l.pos])):
+			l.pos++
+		case strings.HasPrefix(l.src[l.pos:], "//"):
+			if i := strings.IndexByte(l.src[l.pos:], '\n'); i != -1 {
+				l.pos += i + 1
+			} else {
+				l.pos = len(l.src)
+			}
+		case strings.HasPrefix(l.src[l.pos:], "/*"):
+			depth := 0
+			for l.pos < len(l.src) {
+				if strings.HasPrefix(l.src[l.pos:], "/*") {
+					depth++
+					l.pos += 2
+				} else if strings.HasPrefix(l.src[l.pos:], "*/") {
+					depth--
+					l.pos += 2
+					if depth == 0 {
+						break
+					}
+				} else {
+					l.pos++
+				}
+			}
+		default:
+			return
+		}
+	}
+}
+
+// next returns the next token. Strings may be raw (r'…') or triple-quoted;
+// escapes are kept as written.
+func (l *dartLexer) next() dartToken {
+	l.skipSpace()
+	if l.pos >= len(l.src) {
+		return dartToken{Start: l.pos, End: l.pos}
+	}
+	start := l.pos
+	raw := false
+	if l.src[l.pos] == 'r' && l.pos+1 < len(l.src) && (l.src[l.pos+1] == '\'' || l.src[l.pos+1] == '"') {
+		raw = true
+		l.pos++
+	}
+	c := l.src[l.pos]
+	switch {
+	case c == '\'' || c == '"':
+		quote := string(c)
+		if strings.HasPrefix(l.src[l.pos:], strings.Repeat(quote, 3)) {
+			quote = strings.Repeat(quote, 3)
+		}
+		l.pos += len(quote)
+		valueStart := l.pos
+		for l.pos < len(l.src) && !strings.HasPrefix(l.src[l.pos:], quote) {
+			if len(quote) == 1 && l.src[l.pos] == '\n' {
+				break
+			}
+			if l.src[l.pos] == '\\' && !raw {
+				l.pos++
+			}
+			l.pos++
+		}
+		valueEnd := min(l.pos, len(l.src))
+		l.pos = valueEnd
+		if strings.HasPrefix(l.src[l.pos:], quote) {
+			l.pos += len(quote)
+		}
+		return dartToken{Kind: 's', Text: l.src[valueStart:valueEnd], Start: start, End: l.pos, ValueStart: valueStart}
+	case isIdentByte(c):
+		for l.pos < len(l.src) && isIdentByte(l.src[l.pos]) {
+			l.pos++
+		}
+		return dartToken{Kind: 'i', Text: l.src[start:l.pos], Start: start, End: l.pos}
+	default:
+		l.pos++
+		return dartToken{Kind: 'p', Text: string(c), Start: start, End: l.pos}
+	}
+}
+
+// skipAnnotation skips the rest of a metadata annotation after its '@': a
+// qualified name and an optional argument list.
+func (l *dartLexer) skipAnnotation() {
+	for {
+		tok := l.next()
+		if tok.Kind != 'i' {
+			l.pos = tok.Start
+			return
+		}
+		save := l.pos
+		if l.next().Text != "." {
+			l.pos = save
+			break
+		}
+	}
+	save := l.pos
+	if l.next().Text != "(" {
+		l.pos = save
+		return
+	}
+	for depth := 1; depth > 0; {
+		tok := l.next()
+		switch {
+		case tok.Kind == 0:
+			return
+		case tok.Text == "(":
+			depth++
+		case tok.Text == ")":
+			depth--
+		}
+	}
+}
+
+// parseDirectives returns the directives of a Dart file in order. Scanning
+// stops at the first declaration, so text in comments, strings and code is
+// never taken for a directive, and a directive may span several lines.
+func parseDirectives(content string) []dartDirective {
+	l := &dartLexer{src: content}
+	if strings.HasPrefix(content, "#!") {
+		if i := strings.IndexByte(content, '\n'); i != -1 {
+			l.pos = i + 1
+		} else {
+			return nil
+		}
+	}
+	var directives []dartDirective
+	for {
+		tok := l.next()
+		if tok.Text == "@" {
+			l.skipAnnotation()
+			continue
+		}
+		if tok.Kind != 'i' || (tok.Text != dirImport && tok.Text != dirExport && tok.Text != dirPart && tok.Text != dirLibrary) {
+			return directives
+		}
+		d := dartDirective{Keyword: tok.Text, Start: tok.Start}
+		prev := ""
+		for {
+			t := l.next()
+			if t.Kind == 0 {
+				return directives
+			}
+			if t.Text == ";" {
+				d.End = t.End
+				break
+			}
+			switch {
+			case t.Kind == 's':
+				d.URIs = append(d.URIs, dartURI{Value: t.Text, Start: t.ValueStart, End: t.ValueStart + len(t.Text)})
+			case d.Keyword == dirPart && prev == "" && t.Text == "of":
+				d.Keyword = dirPartOf
+			case prev == "as" && t.Kind == 'i':
+				d.Prefix = t.Text
+			}
+			prev = t.Text
+		}
+		d.Line = strings.Count(content[:d.Start], "\n") + 1
+		d.EndLine = d.Line + strings.Count(content[d.Start:d.End], "\n")
+		directives = append(directives, d)
+	}
+}
+
+// directiveOf parses a single directive such as "import 'a.dart' as a;".
+func directiveOf(line string) (dartDirective, bool) {
+	ds := parseDirectives(line)
+	if len(ds) == 0 {
+		return dartDirective{}, false
+	}
+	return ds[0], true
+}
+
+// sameDirective reports whether d has the keyword, URI and prefix of the
+// directive line.
+func sameDirective(d dartDirective, line string) bool {
+	want, ok := directiveOf(line)
+	return ok && d.Keyword == want.Keyword && d.URI() == want.URI() && d.Prefix == want.Prefix
+}
+
+// hasDirective reports whether content already has the directive line, however
+// it is quoted, spaced or wrapped.
+func hasDirective(content, line string) bool {
+	for _, d := range parseDirectives(content) {
+		if sameDirective(d, line) {
+			return true
+		}
+	}
+	return false
+}
+
+// directiveLines returns the 0-based indexes of the lines directives span.
+func directiveLines(directives []dartDirective) map[int]bool {
+	lines := map[int]bool{}
+	for _, d := range directives {
+		for i := d.Line; i <= d.EndLine; i++ {
+			lines[i-1] = true
+		}
+	}
+	return lines
+}
+
+// lineStart returns the offset of the start of the line holding offset.
+func lineStart(content string, offset int) int {
+	return strings.LastIndex(content[:offset], "\n") + 1
+}
+
+// lineAfter returns the offset just past the newline ending the line holding
+// offset, or len(content) on the last line.
+func lineAfter(content string, offset int) int {
+	if i := strings.IndexByte(content[offset:], '\n'); i != -1 {
+		return offset + i + 1
+	}
+	return len(content)
+}
+
+// directiveSpan returns the text to cut to remove d: its whole lines, with a
+// trailing line comment, when nothing else is on them, else just d.
+func directiveSpan(content string, d dartDirective) (int, int) {
+	start, end := lineStart(content, d.Start), lineAfter(content, d.End)
+	before := content[start:d.Start]
+	after := strings.TrimSpace(content[d.End:end])
+	if strings.TrimSpace(before) != "" || (after != "" && !strings.HasPrefix(after, "//")) {
+		return d.Start, d.End
+	}
+	return start, end
+}
+
+// removeDirectives drops the directives of content for which drop returns
+// true and returns the new content and the number removed. When that empties
+// a section, the blank line above it goes too.
+func removeDirectives(content string, drop func(d dartDirective) bool) (string, int) {
+	type span struct{ start, end int }
+	var spans []span
+	kept := map[int]bool{}
+	removed := 0
+	for _, d := range parseDirectives(content) {
+		if !drop(d) {
+			kept[lineStart(content, d.Start)] = true
+			continue
+		}
+		removed++
+		start, end := directiveSpan(content, d)
+		if n := len(spans); n > 0 && spans[n-1].end == start {
+			spans[n-1].end = end
+		} else {
+			spans = append(spans, span{start, end})
+		}
+	}
+	for i := len(spans) - 1; i >= 0; i-- {
+		start, end := spans[i].start, spans[i].end
+		wholeLines := start == lineStart(content, start) && (end == len(content) || content[end-1] == '\n')
+		if wholeLines && strings.HasSuffix(content[:start], "\n\n") && !kept[end] {
+			start--
+		}
+		content = content[:start] + content[end:]
+	}
+	return content, removed
+}
+
+// editDirectives drops the directives of path for which drop returns true
+// and returns the number removed.
+func editDirectives(path string, drop func(d dartDirective) bool) int {
+	data, err := changes.readFile(path)
+	if err != nil {
+		failf("Failed to read %s: %v", path, err)
+		return 0
+	}
+	content, removed := removeDirectives(string(data), drop)
+	if removed == 0 {
+		return 0
+	}
+	if err := changes.writeFile(path, []byte(content)); err != nil {
+		failf("Failed to update %s: %v", path, err)
+		return 0
+	}
+	return removed
+}
+
+// rewriteURIs replaces every directive URI of content, including those of
+// conditional imports, with what rewrite returns for it.
+func rewriteURIs(content string, rewrite func(uri string) string) string {
+	ds := parseDirectives(content)
+	for i := len(ds) - 1; i >= 0; i-- {
+		for j := len(ds[i].URIs) - 1; j >= 0; j-- {
+			u := ds[i].URIs[j]
+			if newURI := rewrite(u.Value); newURI != u.Value {
+				content = content[:u.Start] + newURI + content[u.End:]
+			}
+		}
+	}
+	return content
+}
+
+// insertLineAt inserts line as a line of its own at offset, which is the start of
+// a line or the end of content.
+func insertLineAt(content string, offset int, line string) string {
+	if offset == len(content) && content != "" && !strings.HasSuffix(content, "\n") {
+		return content + "\n" + line
+	}
+	return content[:offset] + line + "\n" + content[offset:]
+}
+
+// headerEnd returns the offset just past the comments and blank lines at the
+// top of content. Doc comments belong to the declaration below them and end
+// the header.
+func headerEnd(content string) int {
+	offset, inBlock := 0, false
+	for offset < len(content) {
+		end := lineAfter(content, offset)
+		line := strings.TrimSpace(content[offset:end])
+		switch {
+		case inBlock:
+			inBlock = !strings.Contains(line, "*/")
+		case line == "" || strings.HasPrefix(line, "#!") || strings.HasPrefix(line, "//") && !strings.HasPrefix(line, "///"):
+		case strings.HasPrefix(line, "/*") && !strings.HasPrefix(line, "/**"):
+			inBlock = !strings.Contains(line, "*/")
+		default:
+			return offset
+		}
+		offset = end
+	}
+	return offset
+}
+
+// insertImportDirective adds importLine after the last import, else before
+// the first part, then sorts the directives. In a file without either, it
+// goes after the library directive or the header comments, set apart by
+// blank lines. It does nothing when the import is already there.
+func insertImportDirective(content, importLine string) string {
+	line := strings.TrimRight(importLine, "\n")
+	if hasDirective(content, line) {
+		return content
+	}
+	var lastImport, firstPart, library *dartDirective
+	ds := parseDirectives(content)
+	for i := range ds {
+		switch ds[i].Keyword {
+		case dirImport:
+			lastImport = &ds[i]
+		case dirPart:
+			if firstPart == nil {
+				firstPart = &ds[i]
+			}
+		case dirLibrary:
+			library = &ds[i]
+		}
+	}
+	switch {
+	case lastImport != nil:
+		return sortDirectives(insertLineAt(content, lineAfter(content, lastImport.End), line))
+	case firstPart != nil:
+		return sortDirectives(insertLineAt(content, lineStart(content, firstPart.Start), line))
+	}
+
+	offset := headerEnd(content)
+	if library != nil {
+		offset = lineAfter(content, library.End)
+	}
+	head := strings.TrimRight(content[:offset], "\n")
+	if head != "" {
+		head += "\n\n"
+	}
+	rest := strings.TrimLeft(content[offset:], "\n")
+	if rest != "" {
+		rest = "\n" + rest
+	}
+	return sortDirectives(head + line + "\n" + rest)
+}
+
+// normalizeImportPartOrder moves imports that follow a part directive above
+// it, after the imports already there.
+func normalizeImportPartOrder(content string) string {
+	ds := parseDirectives(content)
+	firstPart := -1
+	var misplaced []string
+	for _, d := range ds {
+		switch {
+		case d.Keyword == dirPart && firstPart == -1:
+			firstPart = d.Start
+		case d.Keyword == dirImport && firstPart != -1:
+			misplaced = append(misplaced, content[d.Start:d.End])
+		}
+	}
+	if len(misplaced) == 0 {
+		return content
+	}
+	content, _ = removeDirectives(content, func(d dartDirective) bool {
+		return d.Keyword == dirImport && d.Start > firstPart
+	})
+
+	offset := -1
+	for _, d := range parseDirectives(content) {
+		if d.Keyword == dirPart {
+			if offset == -1 {
+				offset = lineStart(content, d.Start)
+			}
+			break
+		}
+		if d.Keyword == dirImport {
+			offset = lineAfter(content, d.End)
+		}
+	}
+	return insertLineAt(content, offset, strings.Join(misplaced, "\n"))
+}
+
+// directiveSection orders directives the way the directives_ordering lint
+// expects: dart:, package: and relative imports, then exports grouped the
+// same way, then parts.
+func directiveSection(d dartDirective) int {
+	section := 0
+	switch d.Keyword {
+	case dirExport:
+		section = 3
+	case dirPart:
+		return 6
+	}
+	switch uri := d.URI(); {
+	case strings.HasPrefix(uri, "dart:"):
+		return section
+	case strings.HasPrefix(uri, "package:"):
+		return section + 1
+	default:
+		return section + 2
+	}
+}
+
+// trimBlankLines drops the blank lines at both ends of s.
+func trimBlankLines(s string) string {
+	lines := strings.Split(s, "\n")
+	for len(lines) > 0 && strings.TrimSpace(lines[0]) == "" {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return strings.Join(lines, "\n")
+}
+
+// attachedComments returns the start of the comment lines right above the
+// line at offset, not above floor, with no blank line between them and it.
+func attachedComments(content string, offset, floor int) int {
+	for offset > floor {
+		prev := lineStart(content, offset-1)
+		line := strings.TrimSpace(content[prev:offset])
+		switch {
+		case strings.HasPrefix(line, "//"):
+			offset = prev
+		case strings.HasSuffix(line, "*/"):
+			open := strings.LastIndex(content[floor:offset], "/*")
+			if open == -1 {
+				return offset
+			}
+			open += floor
+			if ls := lineStart(content, open); strings.TrimSpace(content[ls:open]) == "" {
+				offset = ls
+			} else {
+				return offset
+			}
+		default:
+			return offset
+		}
+	}
+	return offset
+}
+
+// sortDirectives groups and sorts the imports, exports and parts of content
+// by directiveSection, then URI, with a blank line between sections.
+// Comments above a directive and after it on its line move with it. Files
+// with two directives on a line are left alone.
+func sortDirectives(content string) string {
+	type chunk struct {
+		section int
+		uri     string
+		text    string
+	}
+	var chunks []chunk
+	floor, blockStart, blockEnd := 0, -1, -1
+	for _, d := range parseDirectives(content) {
+		if d.Keyword == dirLibrary || d.Keyword == dirPartOf {
+			if blockStart != -1 {
+				return content
+			}
+			floor = lineAfter(content, d.End)
+			continue
+		}
+		start := lineStart(content, d.Start)
+		if blockStart == -1 {
+			blockStart = attachedComments(content, start, floor)
+		} else if start < blockEnd {
+			return content
+		}
+		from := max(blockStart, blockEnd)
+		text := content[start:lineAfter(content, d.End)]
+		if comments := trimBlankLines(content[from:start]); comments != "" {
+			text = comments + "\n" + text
+		}
+		chunks = append(chunks, chunk{directiveSection(d), d.URI(), strings.TrimRight(text, "\n")})
+		blockEnd = lineAfter(content, d.End)
+	}
+	if len(chunks) < 2 {
+		return content
+	}
+
+	sort.SliceStable(chunks, func(i, j int) bool {
+		if chunks[i].section != chunks[j].section {
+			return chunks[i].section < chunks[j].section
+		}
+		return chunks[i].uri < chunks[j].uri
+	})
+	var b strings.Builder
+	for i, c := range chunks {
+		if i > 0 {
+			b.WriteString("\n")
+			if c.section != chunks[i-1].section {
+				b.WriteString("\n")
+			}
+		}
+		b.WriteString(c.text)
+	}
+	if blockEnd < len(content) || strings.HasSuffix(content, "\n") {
+		b.WriteString("\n")
+	}
+	return content[:blockStart] + b.String() + content[blockEnd:]
+}
@@ -0,0 +1,243 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const directivesSource = `#!/usr/bin/env dart
+// Copyright header with import 'header.dart'; in a comment.
+/* import 'commented.dart';
+   /* nested */ export 'still_commented.dart';
+*/
+@TestOn('vm')
+library shop.router;
+
+import 'package:flutter/material.dart'
+    show
+        Widget,
+        BuildContext;
+import "package:go_router/go_router.dart" hide GoRouterHelper;
+import 'src/io_stub.dart'
+    if (dart.library.io) 'src/io.dart'
+    if (dart.library.html) 'src/web.dart';
+import 'package:shop/features/orders/orders.dart' deferred as orders;
+export 'src/public.dart'; // keep
+part 'router.g.dart';
+
+const note = "import 'not_a_directive.dart';";
+import 'after_code.dart';
+`
+
+func TestParseDirectives(t *testing.T) {
+	ds := parseDirectives(directivesSource)
+	type want struct {
+		keyword, uri, prefix string
+		uris, line, endLine  int
+	}
+	wants := []want{
+		{dirLibrary, "", "", 0, 7, 7},
+		{dirImport, "package:flutter/material.dart", "", 1, 9, 12},
+		{dirImport, "package:go_router/go_router.dart", "", 1, 13, 13},
+		{dirImport, "src/io_stub.dart", "", 3, 14, 16},
+		{dirImport, "package:shop/features/orders/orders.dart", "orders", 1, 17, 17},
+		{dirExport, "src/public.dart", "", 1, 18, 18},
+		{dirPart, "router.g.dart", "", 1, 19, 19},
+	}
+	if len(ds) != len(wants) {
+		t.Fatalf("expected %d directives, got %+v", len(wants), ds)
+	}
+	for i, w := range wants {
+		d := ds[i]
+		if d.Keyword != w.keyword || d.URI() != w.uri || d.Prefix != w.prefix || len(d.URIs) != w.uris || d.Line != w.line || d.EndLine != w.endLine {
+			t.Fatalf("directive %d: expected %+v, got %+v", i, w, d)
+		}
+	}
+	if got := ds[3].URIs[2].Value; got != "src/web.dart" {
+		t.Fatalf("expected the web configuration URI, got %q", got)
+	}
+
+	partOf := parseDirectives("part of 'router.dart';\n\nclass A {}\n")
+	if len(partOf) != 1 || partOf[0].Keyword != dirPartOf || partOf[0].URI() != "router.dart" {
+		t.Fatalf("expected a part of directive, got %+v", partOf)
+	}
+	if named := parseDirectives("part of shop.router;\n"); len(named) != 1 || named[0].Keyword != dirPartOf || named[0].URI() != "" {
+		t.Fatalf("expected a part of naming its library, got %+v", named)
+	}
+}
+
+func TestHasDirective(t *testing.T) {
+	if !hasDirective(directivesSource, `import 'package:go_router/go_router.dart';`) {
+		t.Fatalf("expected a double-quoted import to match")
+	}
+	if !hasDirective(directivesSource, `import 'package:shop/features/orders/orders.dart' as orders;`) {
+		t.Fatalf("expected a deferred import to match its prefix")
+	}
+	for _, missing := range []string{
+		"import 'commented.dart';",
+		"import 'not_a_directive.dart';",
+		"import 'after_code.dart';",
+		"import 'package:shop/features/orders/orders.dart';",
+	} {
+		if hasDirective(directivesSource, missing) {
+			t.Fatalf("expected %s not to be found", missing)
+		}
+	}
+}
+
+func TestInsertImportDirectiveAfterMultiLineImport(t *testing.T) {
+	input := "import 'a.dart'\n    show A,\n        B;\n\npart 'x.g.dart';\n\nclass C {}\n"
+	got := insertImportDirective(input, "import 'b.dart';\n")
+	want := "import 'a.dart'\n    show A,\n        B;\nimport 'b.dart';\n\npart 'x.g.dart';\n\nclass C {}\n"
+	if got != want {
+		t.Fatalf("expected:\n%s\ngot:\n%s", want, got)
+	}
+	if again := insertImportDirective(got, `import "b.dart";`); again != got {
+		t.Fatalf("expected the import not to be added twice, got:\n%s", again)
+	}
+
+	got = insertImportDirective("/* import 'old.dart'; */\nlibrary;\n\nclass C {}\n", "import 'b.dart';")
+	if got != "/* import 'old.dart'; */\nlibrary;\n\nimport 'b.dart';\n\nclass C {}\n" {
+		t.Fatalf("expected the import after the library directive, got:\n%s", got)
+	}
+}
+
+func TestInsertImportDirectiveKeepsHeader(t *testing.T) {
+	for _, tt := range []struct{ input, want string }{
+		{"// header\nvoid main() {}\n", "// header\n\nimport 'a.dart';\n\nvoid main() {}\n"},
+		{"/*\n * License.\n */\n\n/// Runs the app.\nvoid main() {}\n", "/*\n * License.\n */\n\nimport 'a.dart';\n\n/// Runs the app.\nvoid main() {}\n"},
+		{"void main() {}\n", "import 'a.dart';\n\nvoid main() {}\n"},
+		{"", "import 'a.dart';\n"},
+	} {
+		if got := insertImportDirective(tt.input, "import 'a.dart';"); got != tt.want {
+			t.Fatalf("expected:\n%s\ngot:\n%s", tt.want, got)
+		}
+	}
+}
+
+func TestNormalizeImportPartOrderMovesMultiLineImport(t *testing.T) {
+	input := "import 'a.dart';\npart 'x.g.dart';\nimport 'b.dart'\n    hide B;\n\nclass C {}\n"
+	got := normalizeImportPartOrder(input)
+	want := "import 'a.dart';\nimport 'b.dart'\n    hide B;\npart 'x.g.dart';\n\nclass C {}\n"
+	if got != want {
+		t.Fatalf("expected:\n%s\ngot:\n%s", want, got)
+	}
+}
+
+func TestRemoveDirectivesAndRepointImports(t *testing.T) {
+	got, n := removeDirectives(directivesSource, func(d dartDirective) bool {
+		return d.Keyword == dirExport || d.URI() == "src/io_stub.dart"
+	})
+	if n != 2 || strings.Contains(got, "src/io") || strings.Contains(got, "src/public.dart") || strings.Contains(got, "// keep") {
+		t.Fatalf("expected the conditional import and the export to be removed, got:\n%s", got)
+	}
+	if !strings.Contains(got, "deferred as orders;\npart 'router.g.dart';") {
+		t.Fatalf("expected the neighbouring directives to be kept, got:\n%s", got)
+	}
+
+	moves := map[string]string{"lib/core/src/io.dart": "lib/core/platform/io.dart"}
+	got = repointImports(directivesSource, "lib/core/router.dart", "lib/core/router.dart", moves, "shop")
+	if !strings.Contains(got, "if (dart.library.io) 'platform/io.dart'") || !strings.Contains(got, "if (dart.library.html) 'src/web.dart'") {
+		t.Fatalf("expected the configuration URI to be repointed, got:\n%s", got)
+	}
+}
+
+func TestSortDirectives(t *testing.T) {
+	input := `// Copyright header.
+library;
+
+import 'page_names.dart';
+part 'router.g.dart';
+// Widgets come from Flutter.
+import 'package:flutter/material.dart' show Widget;
+export 'src/b.dart';
+import 'dart:async'; // for Future
+/* io */
+import 'src/io_stub.dart'
+    if (dart.library.io) 'src/io.dart';
+export 'package:shop/a.dart';
+import 'package:go_router/go_router.dart';
+
+// AUTO_IMPORTS
+class A {}
+`
+	want := `// Copyright header.
+library;
+
+import 'dart:async'; // for Future
+
+// Widgets come from Flutter.
+import 'package:flutter/material.dart' show Widget;
+import 'package:go_router/go_router.dart';
+
+import 'page_names.dart';
+/* io */
+import 'src/io_stub.dart'
+    if (dart.library.io) 'src/io.dart';
+
+export 'package:shop/a.dart';
+
+export 'src/b.dart';
+
+part 'router.g.dart';
+
+// AUTO_IMPORTS
+class A {}
+`
+	got := sortDirectives(input)
+	if got != want {
+		t.Fatalf("expected:\n%s\ngot:\n%s", want, got)
+	}
+	if again := sortDirectives(got); again != got {
+		t.Fatalf("expected sorting to be stable, got:\n%s", again)
+	}
+	if shared := "import 'b.dart'; import 'a.dart';\n"; sortDirectives(shared) != shared {
+		t.Fatalf("expected directives sharing a line to be left alone")
+	}
+}
+
+func TestSortDirectivesMovesCommentsOfFirstDirective(t *testing.T) {
+	for _, tt := range []struct{ input, want string }{
+		{
+			"// the widgets\nimport 'package:z/z.dart';\nimport 'dart:io';\n",
+			"import 'dart:io';\n\n// the widgets\nimport 'package:z/z.dart';\n",
+		},
+		{
+			"// Copyright header.\n\n/* the widgets */\nimport 'package:z/z.dart';\nimport 'dart:io';\n\nvoid main() {}\n",
+			"// Copyright header.\n\nimport 'dart:io';\n\n/* the widgets */\nimport 'package:z/z.dart';\n\nvoid main() {}\n",
+		},
+	} {
+		if got := sortDirectives(tt.input); got != tt.want {
+			t.Fatalf("expected:\n%s\ngot:\n%s", tt.want, got)
+		}
+	}
+}
+
+func TestRemoveDirectivesDropsEmptiedSection(t *testing.T) {
+	input := "import 'package:a/a.dart';\n\nimport 'b.dart';\n// AUTO_IMPORTS\n"
+	got, n := removeDirectives(input, func(d dartDirective) bool { return d.URI() == "b.dart" })
+	if n != 1 || got != "import 'package:a/a.dart';\n// AUTO_IMPORTS\n" {
+		t.Fatalf("expected the emptied section and its blank line to go, got %d:\n%s", n, got)
+	}
+	input = "import 'package:a/a.dart';\n\nimport 'b.dart';\nimport 'c.dart';\n"
+	got, _ = removeDirectives(input, func(d dartDirective) bool { return d.URI() == "b.dart" })
+	if got != "import 'package:a/a.dart';\n\nimport 'c.dart';\n" {
+		t.Fatalf("expected the blank line before a kept import to stay, got:\n%s", got)
+	}
+}
+
+func TestRouterImportsStaySorted(t *testing.T) {
+	withTempDir(t)
+	_ = runMain(t, "new", "page", "orders", "detail")
+	_ = runMain(t, "new", "page", "cart", "summary")
+	router := mustReadFile(t, filepath.Join("lib", "core", "router.dart"))
+	want := "import 'package:riverpod_annotation/riverpod_annotation.dart';\n\n" +
+		"import '../features/cart/presentation/pages/summary_page.dart';\n" +
+		"import '../features/orders/presentation/pages/detail_page.dart';\n" +
+		"import 'page_names.dart';\n\n" +
+		"part 'router.g.dart';\n"
+	if !strings.Contains(router, want) {
+		t.Fatalf("expected grouped and sorted directives, got:\n%s", router)
+	}
+}
@@ -0,0 +1,236 @@
+// fields.go
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// dartField is one field of a generated class, parsed from a "name:Type" spec.
+type dartField struct {
+	Name string
+	Type string
+	// Key is the JSON key when it differs from Name.
+	Key string
+}
+
+var (
+	fieldNameRe = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+	fieldTypeRe = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_<>,?]*$`)
+)
+
+var dartReservedWords = map[string]bool{
+	"assert": true, "break": true, "case": true, "catch": true, "class": true, "const": true,
+	"continue": true, "default": true, "do": true, "else": true, "enum": true, "extends": true,
+	"false": true, "final": true, "finally": true, "for": true, "if": true, "in": true,
+	"is": true, "new": true, "null": true, "rethrow": true, "return": true, "super": true,
+	"switch": true, "this": true, "throw": true, "true": true, "try": true, "var": true,
+	"void": true, "while": true, "with": true,
+}
+
+// defaultEntityFields is used when new entity is given no field specs.
+var defaultEntityFields = []dartField{{Name: "id", Type: "int"}}
+
+// parseFieldSpecs parses specs such as "id:int", "price:double?" and
+// "tags:List<String>". snake_case names are converted to camelCase.
+func parseFieldSpecs(specs []string) ([]dartField, error) {
+	fields := make([]dartField, 0, len(specs))
+	seen := map[string]bool{}
+	for _, spec := range specs {
+		name, typ, ok := strings.Cut(spec, ":")
+		if !ok || name == "" || typ == "" {
+			return nil, fmt.Errorf("invalid field spec %q (expected name:Type)", spec)
+		}
+		field, err := newDartField(name, typ)
+		if err != nil {
+			return nil, fmt.Errorf("invalid field spec %q: %w", spec, err)
+		}
+		if seen[field.Name] {
+			return nil, fmt.Errorf("duplicate field %q", field.Name)
+		}
+		seen[field.Name] = true
+		fields = append(fields, field)
+	}
+	return fields, nil
+}
+
+// splitSpecList splits option values such as "id:int, tags:Map<String, int>"
+// into field specs at commas and spaces outside generic arguments.
+func splitSpecList(values []string) []string {
+	var specs []string
+	for _, v := range values {
+		depth, start := 0, 0
+		for i, r := range v + "," {
+			switch {
+			case r == '<':
+				depth++
+			case r == '>':
+				depth--
+			case (r == ',' || r == ' ') && depth == 0:
+				if spec := strings.TrimSpace(v[start:i]); spec != "" {
+					specs = append(specs, spec)
+				}
+				start = i + 1
+			}
+		}
+	}
+	return specs
+}
+
+func newDartField(name, typ string) (dartField, error) {
+	if !fieldNameRe.MatchString(name) {
+		return dartField{}, fmt.Errorf("%q is not a valid Dart identifier", name)
+	}
+	if strings.Contains(name, "_") {
+		name = camelCase(name)
+	}
+	if dartReservedWords[name] {
+		return dartField{}, fmt.Errorf("%q is a reserved word in Dart", name)
+	}
+	typ, err := normalizeDartType(typ)
+	if err != nil {
+		return dartField{}, err
+	}
+	return dartField{Name: name, Type: typ}, nil
+}
+
+// normalizeDartType validates a type and formats generic arguments as
+// "Map<String, int>".
+func normalizeDartType(typ string) (string, error) {
+	typ = strings.ReplaceAll(typ, " ", "")
+	if !fieldTypeRe.MatchString(typ) {
+		return "", fmt.Errorf("%q is not a valid Dart type", typ)
+	}
+	depth := 0
+	for i, r := range typ {
+		switch r {
+		case '<':
+			depth++
+		case '>':
+			depth--
+			if depth < 0 {
+				return "", fmt.Errorf("unbalanced generics in %q", typ)
+			}
+		case ',':
+			if depth == 0 {
+				return "", fmt.Errorf("unexpected ',' in %q", typ)
+			}
+		case '?':
+			if i+1 < len(typ) && typ[i+1] != '>' && typ[i+1] != ',' {
+				return "", fmt.Errorf("misplaced '?' in %q", typ)
+			}
+		}
+	}
+	if depth != 0 {
+		return "", fmt.Errorf("unbalanced generics in %q", typ)
+	}
+	if strings.Contains(typ, "??") {
+		return "", fmt.Errorf("misplaced '?' in %q", typ)
+	}
+	return strings.ReplaceAll(typ, ",", ", "), nil
+}
+
+// Nullable reports whether the field type ends with '?'.
+func (f dartField) Nullable() bool {
+	return strings.HasSuffix(f.Type, "?")
+}
+
+// BaseType is the field type without a trailing '?'.
+func (f dartField) BaseType() string {
+	return strings.TrimSuffix(f.Type, "?")
+}
+
+// NullableType is the field type with a trailing '?', as used by copyWith.
+func (f dartField) NullableType() string {
+	return f.BaseType() + "?"
+}
+
+// Collection returns "List", "Set" or "Map" for collection fields, else "".
+func (f dartField) Collection() string {
+	base := f.BaseType()
+	for _, kind := range []string{"List", "Set", "Map"} {
+		if base == kind || strings.HasPrefix(base, kind+"<") {
+			return kind
+		}
+	}
+	return ""
+}
+
+// TypeArgs returns the generic arguments of the field type, e.g. ["String",
+// "int"] for Map<String, int>.
+func (f dartField) TypeArgs() []string {
+	base := f.BaseType()
+	open := strings.Index(base, "<")
+	if open == -1 || !strings.HasSuffix(base, ">") {
+		return nil
+	}
+	inner := base[open+1 : len(base)-1]
+	var args []string
+	depth, start := 0, 0
+	for i, r := range inner {
+		switch r {
+		case '<':
+			depth++
+		case '>':
+			depth--
+		case ',':
+			if depth == 0 {
+				args = append(args, strings.TrimSpace(inner[start:i]))
+				start = i + 1
+			}
+		}
+	}
+	return append(args, strings.TrimSpace(inner[start:]))
+}
+
+// EqualsExpr compares the field with the same field of other, using the
+// deep equality helpers of package:flutter/foundation.dart for collections.
+func (f dartField) EqualsExpr(other string) string {
+	switch f.Collection() {
+	case "List":
+		return fmt.Sprintf("listEquals(%s, %s.%s)", f.Name, other, f.Name)
+	case "Set":
+		return fmt.Sprintf("setEquals(%s, %s.%s)", f.Name, other, f.Name)
+	case "Map":
+		return fmt.Sprintf("mapEquals(%s, %s.%s)", f.Name, other, f.Name)
+	default:
+		return fmt.Sprintf("%s == %s.%s", f.Name, other, f.Name)
+	}
+}
+
+// HashExpr is the field's contribution to hashCode, hashing collections by
+// content.
+func (f dartField) HashExpr() string {
+	value := f.Name
+	switch f.Collection() {
+	case "List":
+		if f.Nullable() {
+			value = "(" + f.Name + " ?? const [])"
+		}
+		return "Object.hashAll(" + value + ")"
+	case "Set":
+		if f.Nullable() {
+			value = "(" + f.Name + " ?? const {})"
+		}
+		return "Object.hashAllUnordered(" + value + ")"
+	case "Map":
+		if f.Nullable() {
+			value = "(" + f.Name + " ?? const {})"
+		}
+		return "Object.hashAllUnordered(" + value + ".entries.map((e) => Object.hash(e.key, e.value)))"
+	default:
+		return f.Name
+	}
+}
+
+// needsFoundation reports whether any field compares with the collection
+// helpers from package:flutter/foundation.dart.
+func needsFoundation(fields []dartField) bool {
+	for _, f := range fields {
+		if f.Collection() != "" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseFieldSpecs(t *testing.T) {
+	fields, err := parseFieldSpecs([]string{"id:int", "display_name:String", "price:double?", "tags:List<String>", "meta:Map<String,int>?"})
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+	expect := []dartField{
+		{Name: "id", Type: "int"},
+		{Name: "displayName", Type: "String"},
+		{Name: "price", Type: "double?"},
+		{Name: "tags", Type: "List<String>"},
+		{Name: "meta", Type: "Map<String, int>?"},
+	}
+	for i, f := range expect {
+		if fields[i] != f {
+			t.Fatalf("field %d: expected %+v, got %+v", i, f, fields[i])
+		}
+	}
+	if fields[4].Collection() != "Map" || !fields[4].Nullable() || strings.Join(fields[4].TypeArgs(), "|") != "String|int" {
+		t.Fatalf("unexpected map field info: %+v", fields[4])
+	}
+
+	for _, bad := range []string{"id", "id:", "1id:int", "class:int", "x:List<int", "x:int??", "x:Map<String>>"} {
+		if _, err := parseFieldSpecs([]string{bad}); err == nil {
+			t.Fatalf("expected error for %q", bad)
+		}
+	}
+	if _, err := parseFieldSpecs([]string{"id:int", "id:String"}); err == nil {
+		t.Fatalf("expected duplicate field error")
+	}
+}
+
+func TestNewEntityWithFieldSpecs(t *testing.T) {
+	withTempDir(t)
+	_ = runMain(t, "new", "entity", "shop", "product", "id:int", "name:String", "price:double?", "tags:List<String>")
+
+	content := mustReadFile(t, filepath.Join("lib", "features", "shop", "domain", "entities", "product.dart"))
+	for _, expect := range []string{
+		"import 'package:flutter/foundation.dart';",
+		"  final double? price;",
+		"  final List<String> tags;",
+		"  const Product({\n    required this.id,\n    required this.name,\n    this.price,\n    required this.tags,\n  });",
+		"    double? price,",
+		"      tags: tags ?? this.tags,",
+		"          listEquals(tags, other.tags);",
+		"        Object.hashAll(tags),",
+		"'Product(id: $id, name: $name, price: $price, tags: $tags)'",
+	} {
+		if !strings.Contains(content, expect) {
+			t.Fatalf("expected entity to contain %q, got:\n%s", expect, content)
+		}
+	}
+
+	out := runMain(t, "new", "entity", "shop", "broken", "id")
+	if !strings.Contains(out, "invalid field spec") {
+		t.Fatalf("expected invalid field spec error, got:\n%s", out)
+	}
+}
@@ -7,6 +7,7 @@ toolchain go1.24.1
 require (
 	golang.org/x/oauth2 v0.33.0
 	google.golang.org/api v0.257.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
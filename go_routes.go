@@ -0,0 +1,217 @@
+// go_routes.go
+package main
+
+import (
+	"regexp"
+	"strings"
+)
+
+// goRoute is a GoRoute(...) call in router.dart. dart format splits the
+// generated one-line routes over several lines, so routes are found by
+// parsing rather than line by line. Offsets are bytes of the source.
+type goRoute struct {
+	Start, End         int    // from "GoRoute" to just past its closing parenthesis
+	Path               string // the path argument as written, e.g. kDetailPage or 'edit'
+	PathStart, PathEnd int
+	Class              string // the page class its builder returns, e.g. orders.DetailPage
+	Routes, RoutesEnd  int    // the brackets of its routes list, or -1
+}
+
+// goRouteFrame is an open parenthesis, bracket or brace. route is the GoRoute
+// whose arguments it holds and list the GoRoute whose routes list it is.
+type goRouteFrame struct {
+	route, list int
+	arg         string // the argument being read, for a GoRoute frame
+}
+
+// parseGoRoutes returns the GoRoutes of content in source order, nested
+// routes included. Comments and strings are skipped.
+func parseGoRoutes(content string) []goRoute {
+	l := &dartLexer{src: content}
+	var toks []dartToken
+	for t := l.next(); t.Kind != 0; t = l.next() {
+		toks = append(toks, t)
+	}
+	text := func(i int) string {
+		if i < 0 || i >= len(toks) || toks[i].Kind == 's' {
+			return ""
+		}
+		return toks[i].Text
+	}
+
+	var routes []goRoute
+	var stack []goRouteFrame
+	for i, t := range toks {
+		if t.Kind == 's' {
+			continue
+		}
+		top := -1
+		if len(stack) > 0 {
+			top = stack[len(stack)-1].route
+		}
+		switch {
+		case t.Text == "(" || t.Text == "[" || t.Text == "{":
+			f := goRouteFrame{route: -1, list: -1}
+			if t.Text == "(" && text(i-1) == "GoRoute" && text(i-2) != "." {
+				routes = append(routes, goRoute{Start: toks[i-1].Start, Routes: -1, RoutesEnd: -1})
+				f.route = len(routes) - 1
+			} else if t.Text == "[" && top != -1 && text(i-1) == ":" && text(i-2) == "routes" {
+				routes[top].Routes = t.Start
+				f.list = top
+			}
+			stack = append(stack, f)
+		case t.Text == ")" || t.Text == "]" || t.Text == "}":
+			if len(stack) == 0 {
+				continue
+			}
+			f := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			if f.route != -1 {
+				routes[f.route].End = t.End
+			}
+			if f.list != -1 {
+				routes[f.list].RoutesEnd = t.Start
+			}
+		case top == -1:
+		case t.Text == ":" && toks[i-1].Kind == 'i':
+			arg := toks[i-1].Text
+			stack[len(stack)-1].arg = arg
+			if arg != "path" || i+1 == len(toks) {
+				continue
+			}
+			// the path runs to the next comma or parenthesis at this depth
+			j, depth := i+1, 0
+			for ; j < len(toks); j++ {
+				s := text(j)
+				if depth == 0 && (s == "," || s == ")") {
+					break
+				}
+				switch s {
+				case "(", "[", "{":
+					depth++
+				case ")", "]", "}":
+					depth--
+				}
+			}
+			if j > i+1 {
+				r := &routes[top]
+				r.PathStart, r.PathEnd = toks[i+1].Start, toks[j-1].End
+				r.Path = content[r.PathStart:r.PathEnd]
+			}
+		case t.Text == "=" && text(i+1) == ">" && toks[i+1].Start == t.End && stack[len(stack)-1].arg == "builder":
+			j := i + 2
+			if text(j) == "const" {
+				j++
+			}
+			if j >= len(toks) || toks[j].Kind != 'i' {
+				continue
+			}
+			class := toks[j].Text
+			if text(j+1) == "." && j+2 < len(toks) && toks[j+2].Kind == 'i' {
+				class += "." + text(j+2)
+				j += 2
+			}
+			if text(j+1) == "(" {
+				routes[top].Class = class
+			}
+		}
+	}
+
+	closed := routes[:0]
+	for _, r := range routes {
+		if r.End > 0 {
+			closed = append(closed, r)
+		}
+	}
+	return closed
+}
+
+// routesOfPage returns the GoRoutes building the page or using its route
+// constant as path.
+func routesOfPage(content, feature, pageName string) []goRoute {
+	class := pageRouteClass(feature, pageName)
+	constName := pageConstName(feature, pageName)
+	var found []goRoute
+	for _, r := range parseGoRoutes(content) {
+		if r.Class == class || r.Path == constName {
+			found = append(found, r)
+		}
+	}
+	return found
+}
+
+// hasPageRoute reports whether content routes the page.
+func hasPageRoute(content, feature, pageName string) bool {
+	return len(routesOfPage(content, feature, pageName)) > 0
+}
+
+var (
+	// branchHeadRe and branchTailRe match what surrounds a GoRoute that is the
+	// only route of a StatefulShellBranch.
+	branchHeadRe = regexp.MustCompile(`StatefulShellBranch\(\s*routes:\s*\[\s*$`)
+	branchTailRe = regexp.MustCompile(`^\s*,?\s*\]\s*,?\s*\)`)
+)
+
+// goRouteSpan returns the text to cut to remove r: the shell branch holding
+// only r, or r with its trailing comma, widened to whole lines when nothing
+// else is on them.
+func goRouteSpan(content string, r goRoute) (int, int) {
+	start, end := r.Start, r.End
+	if head := branchHeadRe.FindStringIndex(content[:start]); head != nil {
+		if tail := branchTailRe.FindStringIndex(content[end:]); tail != nil {
+			start, end = head[0], end+tail[1]
+		}
+	}
+	if strings.HasPrefix(content[end:], ",") {
+		end++
+	}
+	rest := strings.TrimSpace(content[end:lineAfter(content, end)])
+	if strings.TrimSpace(content[lineStart(content, start):start]) == "" && (rest == "" || strings.HasPrefix(rest, "//")) {
+		return lineStart(content, start), lineAfter(content, end)
+	}
+	for end < len(content) && content[end] == ' ' {
+		end++
+	}
+	return start, end
+}
+
+// removeGoRoutes cuts the GoRoutes for which drop returns true, with the
+// routes nested in them, and returns the result and how many it dropped.
+func removeGoRoutes(content string, drop func(r goRoute) bool) (string, int) {
+	var spans [][2]int
+	dropped := 0
+	for _, r := range parseGoRoutes(content) {
+		if len(spans) > 0 && r.Start < spans[len(spans)-1][1] {
+			continue
+		}
+		if !drop(r) {
+			continue
+		}
+		start, end := goRouteSpan(content, r)
+		spans = append(spans, [2]int{start, end})
+		dropped++
+	}
+	for i := len(spans) - 1; i >= 0; i-- {
+		content = content[:spans[i][0]] + content[spans[i][1]:]
+	}
+	return content, dropped
+}
+
+// editGoRoutes drops the GoRoutes of path for which drop returns true and
+// stages the result. It returns the number of routes dropped.
+func editGoRoutes(path string, drop func(r goRoute) bool) int {
+	data, err := changes.readFile(path)
+	if err != nil {
+		failf("Failed to read %s: %v", path, err)
+		return 0
+	}
+	content, removed := removeGoRoutes(string(data), drop)
+	if removed == 0 {
+		return 0
+	}
+	if err := changes.writeFile(path, []byte(content)); err != nil {
+		failf("Failed to update %s: %v", path, err)
+		return 0
+	}
+	return removed
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// formattedRouter is router.dart as dart format leaves it, with a nested
+// route, a shell branch and GoRoutes in a comment and a string.
+const formattedRouter = `@riverpod
+Raw<GoRouter> router(Ref ref) => GoRouter(
+  routes: [
+    // GoRoute(path: kOldPage, builder: (context, state) => const OldPage()),
+    GoRoute(
+      path: kDetailPage,
+      builder: (context, state) =>
+          DetailPage(id: int.parse(state.pathParameters['id']!)),
+      routes: [
+        GoRoute(
+          path: 'edit',
+          builder: (context, state) => const EditPage(),
+        ),
+      ],
+    ),
+    StatefulShellRoute.indexedStack(
+      builder: (context, state, navigationShell) =>
+          CartShell(navigationShell: navigationShell),
+      branches: [
+        StatefulShellBranch(
+          routes: [
+            GoRoute(
+              path: kCartPage,
+              builder: (context, state) => const cart.CartPage(),
+            ),
+          ],
+        ),
+      ],
+    ),
+    GoRoute(path: kHomePage, redirect: (context, state) => null, builder: (context, state) => const HomePage()),
+    // AUTO_ROUTES
+  ],
+);
+
+const note = 'GoRoute(path: kNotePage)';
+`
+
+func TestParseGoRoutes(t *testing.T) {
+	routes := parseGoRoutes(formattedRouter)
+	want := []struct{ path, class string }{
+		{"kDetailPage", "DetailPage"},
+		{"'edit'", "EditPage"},
+		{"kCartPage", "cart.CartPage"},
+		{"kHomePage", "HomePage"},
+	}
+	if len(routes) != len(want) {
+		t.Fatalf("expected %d routes, got %+v", len(want), routes)
+	}
+	for i, w := range want {
+		if routes[i].Path != w.path || routes[i].Class != w.class {
+			t.Fatalf("route %d: expected %+v, got %+v", i, w, routes[i])
+		}
+	}
+	detail := routes[0]
+	if !strings.HasSuffix(formattedRouter[:detail.End], "],\n    )") {
+		t.Fatalf("expected the detail route to end after its routes, got %q", formattedRouter[detail.Start:detail.End])
+	}
+	if detail.Routes == -1 || formattedRouter[detail.Routes] != '[' || formattedRouter[detail.RoutesEnd] != ']' {
+		t.Fatalf("expected the routes list of the detail route, got %+v", detail)
+	}
+	if routes[1].Routes != -1 {
+		t.Fatalf("expected no routes list on the edit route, got %+v", routes[1])
+	}
+}
+
+func TestRemoveGoRoutesFormatted(t *testing.T) {
+	got, n := removeGoRoutes(formattedRouter, func(r goRoute) bool { return r.Class == "DetailPage" || r.Class == "EditPage" })
+	if n != 1 || strings.Contains(got, "kDetailPage") || strings.Contains(got, "'edit'") {
+		t.Fatalf("expected the detail route to go with its nested route, got %d:\n%s", n, got)
+	}
+	if !strings.Contains(got, "    // GoRoute(path: kOldPage, builder: (context, state) => const OldPage()),\n    StatefulShellRoute") {
+		t.Fatalf("expected the lines around the route to be kept, got:\n%s", got)
+	}
+
+	got, n = removeGoRoutes(formattedRouter, func(r goRoute) bool { return r.Path == "kCartPage" })
+	if n != 1 || strings.Contains(got, "StatefulShellBranch") || !strings.Contains(got, "      branches: [\n      ],") {
+		t.Fatalf("expected the branch of the cart route to go, got %d:\n%s", n, got)
+	}
+
+	got, n = removeGoRoutes(formattedRouter, func(r goRoute) bool { return r.Class == "HomePage" })
+	if n != 1 || strings.Contains(got, "kHomePage") || !strings.Contains(got, "    ),\n    // AUTO_ROUTES") {
+		t.Fatalf("expected the one-line route to go, got %d:\n%s", n, got)
+	}
+
+	inline := "routes: [GoRoute(path: 'a', builder: (c, s) => A()), GoRoute(path: 'b', builder: (c, s) => B())]"
+	if got, _ := removeGoRoutes(inline, func(r goRoute) bool { return r.Class == "A" }); got != "routes: [GoRoute(path: 'b', builder: (c, s) => B())]" {
+		t.Fatalf("expected the first inline route to go, got %s", got)
+	}
+}
+
+func TestRemovePageDropsFormattedRoute(t *testing.T) {
+	withTempDir(t)
+	_ = runMain(t, "new", "page", "checkout", "cart")
+	_ = runMain(t, "new", "page", "checkout", "payment_method")
+
+	routerPath := filepath.Join("lib", "core", "router.dart")
+	router := mustReadFile(t, routerPath)
+	formatted := strings.Replace(router,
+		"GoRoute(path: kPaymentMethodPage, builder: (context, state) => const PaymentMethodPage()),",
+		"GoRoute(\n      path: kPaymentMethodPage,\n      builder: (context, state) => const PaymentMethodPage(),\n    ),", 1)
+	if formatted == router {
+		t.Fatalf("expected the generated route line in router.dart, got:\n%s", router)
+	}
+	mustWriteFile(t, routerPath, formatted)
+
+	out, code := runMainCode(t, "remove", "page", "checkout", "payment_method")
+	if code != 0 || !strings.Contains(out, "Removed import and route for PaymentMethodPage") {
+		t.Fatalf("expected the route to be reported removed, got code %d:\n%s", code, out)
+	}
+	router = mustReadFile(t, routerPath)
+	if strings.Contains(router, "PaymentMethod") || !strings.Contains(router, "kCartPage") {
+		t.Fatalf("expected only the payment method route to go, got:\n%s", router)
+	}
+	if strings.Contains(router, "    \n") || strings.Contains(router, "),\n\n") {
+		t.Fatalf("expected no lines left over from the route, got:\n%s", router)
+	}
+}
@@ -0,0 +1,295 @@
+// graph.go
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Output formats of farch graph.
+const (
+	graphDOT     = "dot"
+	graphMermaid = "mermaid"
+	graphJSON    = "json"
+)
+
+// Graph levels: one node per feature layer, per feature or per layer.
+const (
+	graphLevelFull    = "full"
+	graphLevelFeature = "feature"
+	graphLevelLayer   = "layer"
+)
+
+// graphRoot names the files of a feature outside its layers, such as the
+// barrel.
+const graphRoot = "root"
+
+type graphNode struct {
+	ID      string `json:"id"`
+	Feature string `json:"feature,omitempty"`
+	Layer   string `json:"layer,omitempty"`
+}
+
+type graphEdge struct {
+	From    string `json:"from"`
+	To      string `json:"to"`
+	Imports int    `json:"imports"`
+	Cycle   bool   `json:"cycle"`
+}
+
+// depGraph is the import graph between groups of Dart files.
+type depGraph struct {
+	Nodes  []graphNode `json:"nodes"`
+	Edges  []graphEdge `json:"edges"`
+	Cycles [][]string  `json:"cycles"`
+}
+
+// graphNodeOf groups a file under lib/ by feature and layer. Files outside
+// lib/features are grouped by their top folder under lib/ ("core"), or as
+// "app" when directly in lib/.
+func graphNodeOf(path, level string) graphNode {
+	loc := locateDart(path)
+	if loc.Feature == "" {
+		parts := strings.Split(filepath.ToSlash(filepath.Clean(path)), "/")
+		if len(parts) > 2 {
+			return graphNode{ID: parts[1]}
+		}
+		return graphNode{ID: "app"}
+	}
+	layer := loc.Layer
+	if layer == "" {
+		layer = graphRoot
+	}
+	switch level {
+	case graphLevelFeature:
+		return graphNode{ID: loc.Feature, Feature: loc.Feature}
+	case graphLevelLayer:
+		return graphNode{ID: layer, Layer: layer}
+	default:
+		return graphNode{ID: loc.Feature + "/" + layer, Feature: loc.Feature, Layer: layer}
+	}
+}
+
+// buildGraph groups the imports of the project files under lib/ at level.
+// With feature set, only edges from or to that feature are kept.
+func buildGraph(level, feature string) (depGraph, error) {
+	imports, _, err := projectImports("lib")
+	if err != nil {
+		return depGraph{}, err
+	}
+	nodes := map[string]graphNode{}
+	counts := map[[2]string]int{}
+	for _, imp := range imports {
+		if feature != "" && locateDart(imp.File).Feature != feature && locateDart(imp.Target).Feature != feature {
+			continue
+		}
+		from, to := graphNodeOf(imp.File, level), graphNodeOf(imp.Target, level)
+		if from.ID == to.ID {
+			continue
+		}
+		nodes[from.ID], nodes[to.ID] = from, to
+		counts[[2]string{from.ID, to.ID}]++
+	}
+
+	g := depGraph{Nodes: []graphNode{}, Edges: []graphEdge{}, Cycles: [][]string{}}
+	for _, n := range nodes {
+		g.Nodes = append(g.Nodes, n)
+	}
+	sort.Slice(g.Nodes, func(i, j int) bool { return g.Nodes[i].ID < g.Nodes[j].ID })
+	for k, n := range counts {
+		g.Edges = append(g.Edges, graphEdge{From: k[0], To: k[1], Imports: n})
+	}
+	sort.Slice(g.Edges, func(i, j int) bool {
+		if g.Edges[i].From != g.Edges[j].From {
+			return g.Edges[i].From < g.Edges[j].From
+		}
+		return g.Edges[i].To < g.Edges[j].To
+	})
+	g.markCycles()
+	return g, nil
+}
+
+// markCycles finds the strongly connected components of more than one node
+// (Tarjan's algorithm) and flags the edges inside them.
+func (g *depGraph) markCycles() {
+	out := map[string][]string{}
+	for _, e := range g.Edges {
+		out[e.From] = append(out[e.From], e.To)
+	}
+	index := map[string]int{}
+	low := map[string]int{}
+	onStack := map[string]bool{}
+	var stack []string
+	component := map[string]int{}
+	next := 0
+
+	var visit func(v string)
+	visit = func(v string) {
+		index[v], low[v] = next, next
+		next++
+		stack = append(stack, v)
+		onStack[v] = true
+		for _, w := range out[v] {
+			if _, seen := index[w]; !seen {
+				visit(w)
+				low[v] = min(low[v], low[w])
+			} else if onStack[w] {
+				low[v] = min(low[v], index[w])
+			}
+		}
+		if low[v] != index[v] {
+			return
+		}
+		var scc []string
+		for {
+			w := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			onStack[w] = false
+			scc = append(scc, w)
+			if w == v {
+				break
+			}
+		}
+		if len(scc) > 1 {
+			sort.Strings(scc)
+			g.Cycles = append(g.Cycles, scc)
+			for _, w := range scc {
+				component[w] = len(g.Cycles)
+			}
+		}
+	}
+	for _, n := range g.Nodes {
+		if _, seen := index[n.ID]; !seen {
+			visit(n.ID)
+		}
+	}
+	sort.Slice(g.Cycles, func(i, j int) bool { return g.Cycles[i][0] < g.Cycles[j][0] })
+	for i, e := range g.Edges {
+		if c := component[e.From]; c != 0 && c == component[e.To] {
+			g.Edges[i].Cycle = true
+		}
+	}
+}
+
+// features returns the features with nodes in the graph and their nodes.
+func (g depGraph) features() ([]string, map[string][]graphNode) {
+	byFeature := map[string][]graphNode{}
+	var names []string
+	for _, n := range g.Nodes {
+		if n.Feature == "" || n.ID == n.Feature {
+			continue
+		}
+		if _, ok := byFeature[n.Feature]; !ok {
+			names = append(names, n.Feature)
+		}
+		byFeature[n.Feature] = append(byFeature[n.Feature], n)
+	}
+	return names, byFeature
+}
+
+// dot renders the graph for Graphviz, clustering the layers of each feature.
+// Edges in a cycle are drawn red.
+func (g depGraph) dot() string {
+	var b strings.Builder
+	b.WriteString("digraph farch {\n  rankdir=LR;\n  node [shape=box];\n")
+	names, byFeature := g.features()
+	clustered := map[string]bool{}
+	for _, f := range names {
+		fmt.Fprintf(&b, "  subgraph %q {\n    label=%q;\n", "cluster_"+f, f)
+		for _, n := range byFeature[f] {
+			fmt.Fprintf(&b, "    %q [label=%q];\n", n.ID, n.Layer)
+			clustered[n.ID] = true
+		}
+		b.WriteString("  }\n")
+	}
+	for _, n := range g.Nodes {
+		if !clustered[n.ID] {
+			fmt.Fprintf(&b, "  %q;\n", n.ID)
+		}
+	}
+	for _, e := range g.Edges {
+		attrs := fmt.Sprintf("label=%q", fmt.Sprint(e.Imports))
+		if e.Cycle {
+			attrs += ", color=red, fontcolor=red"
+		}
+		fmt.Fprintf(&b, "  %q -> %q [%s];\n", e.From, e.To, attrs)
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+var mermaidIDRe = regexp.MustCompile(`[^A-Za-z0-9_]`)
+
+// mermaid renders the graph as a Mermaid flowchart, with a subgraph per
+// feature. Edges in a cycle are drawn red.
+func (g depGraph) mermaid() string {
+	id := func(s string) string { return "n_" + mermaidIDRe.ReplaceAllString(s, "_") }
+	var b strings.Builder
+	b.WriteString("graph LR\n")
+	names, byFeature := g.features()
+	clustered := map[string]bool{}
+	for _, f := range names {
+		fmt.Fprintf(&b, "  subgraph %s [%s]\n", "f_"+mermaidIDRe.ReplaceAllString(f, "_"), f)
+		for _, n := range byFeature[f] {
+			fmt.Fprintf(&b, "    %s[\"%s\"]\n", id(n.ID), n.Layer)
+			clustered[n.ID] = true
+		}
+		b.WriteString("  end\n")
+	}
+	for _, n := range g.Nodes {
+		if !clustered[n.ID] {
+			fmt.Fprintf(&b, "  %s[\"%s\"]\n", id(n.ID), n.ID)
+		}
+	}
+	var cycle []string
+	for i, e := range g.Edges {
+		fmt.Fprintf(&b, "  %s -->|%d| %s\n", id(e.From), e.Imports, id(e.To))
+		if e.Cycle {
+			cycle = append(cycle, fmt.Sprint(i))
+		}
+	}
+	if len(cycle) > 0 {
+		fmt.Fprintf(&b, "  linkStyle %s stroke:red,color:red\n", strings.Join(cycle, ","))
+	}
+	return b.String()
+}
+
+// runGraph prints the dependency graph and returns the exit code.
+func runGraph(format, level, feature string) int {
+	if format == "" {
+		format = graphDOT
+	}
+	if format != graphDOT && format != graphMermaid && format != graphJSON {
+		fmt.Printf("❌ Unknown graph format %q (use dot | mermaid | json)\n", format)
+		return 1
+	}
+	if level == "" {
+		level = graphLevelFull
+	}
+	if level != graphLevelFull && level != graphLevelFeature && level != graphLevelLayer {
+		fmt.Printf("❌ Unknown graph level %q (use full | feature | layer)\n", level)
+		return 1
+	}
+	if _, err := os.Stat(filepath.Join("lib", "features", feature)); feature != "" && err != nil {
+		fmt.Printf("❌ Feature %s not found under lib/features\n", feature)
+		return 1
+	}
+	g, err := buildGraph(level, feature)
+	if err != nil {
+		fmt.Printf("❌ Failed to build the graph: %v\n", err)
+		return 1
+	}
+	switch format {
+	case graphMermaid:
+		fmt.Print(g.mermaid())
+	case graphJSON:
+		printJSON(g)
+	default:
+		fmt.Print(g.dot())
+	}
+	return 0
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeGraphProject(t *testing.T) {
+	t.Helper()
+	mustWriteFile(t, "pubspec.yaml", "name: shop\n")
+	mustWriteFile(t, filepath.Join("lib", "features", "orders", "domain", "entities", "order.dart"),
+		"import '../../../cart/cart.dart';\nimport 'package:shop/core/utils/result.dart';\n")
+	mustWriteFile(t, filepath.Join("lib", "features", "orders", "data", "models", "order_model.dart"),
+		"import '../../domain/entities/order.dart';\nimport 'package:json_annotation/json_annotation.dart';\n")
+	mustWriteFile(t, filepath.Join("lib", "features", "cart", "cart.dart"),
+		"export 'domain/entities/cart.dart';\n")
+	mustWriteFile(t, filepath.Join("lib", "features", "cart", "domain", "entities", "cart.dart"),
+		"import 'package:shop/features/orders/domain/entities/order.dart';\n")
+	mustWriteFile(t, filepath.Join("lib", "core", "utils", "result.dart"), "sealed class Result<T> {}\n")
+}
+
+func TestGraphDOT(t *testing.T) {
+	withTempDir(t)
+	writeGraphProject(t)
+
+	out, code := runMainCode(t, "graph")
+	if code != 0 {
+		t.Fatalf("graph failed:\n%s", out)
+	}
+	for _, expect := range []string{
+		"digraph farch {",
+		"  subgraph \"cluster_orders\" {\n    label=\"orders\";\n    \"orders/data\" [label=\"data\"];\n    \"orders/domain\" [label=\"domain\"];\n  }\n",
+		"  \"core\";\n",
+		"  \"orders/data\" -> \"orders/domain\" [label=\"1\"];\n",
+		"  \"orders/domain\" -> \"core\" [label=\"1\"];\n",
+		"  \"cart/domain\" -> \"orders/domain\" [label=\"1\", color=red, fontcolor=red];\n",
+		"  \"orders/domain\" -> \"cart/root\" [label=\"1\", color=red, fontcolor=red];\n",
+	} {
+		if !strings.Contains(out, expect) {
+			t.Fatalf("expected %q, got:\n%s", expect, out)
+		}
+	}
+}
+
+func TestGraphMermaidAndJSONFilters(t *testing.T) {
+	withTempDir(t)
+	writeGraphProject(t)
+
+	out := runMain(t, "graph", "--format", "mermaid", "--level", "feature")
+	for _, expect := range []string{
+		"graph LR\n",
+		"  n_cart -->|1| n_orders\n",
+		"  n_orders -->|1| n_cart\n",
+		"  n_orders -->|1| n_core\n",
+		"  linkStyle 0,1 stroke:red,color:red\n",
+	} {
+		if !strings.Contains(out, expect) {
+			t.Fatalf("expected %q, got:\n%s", expect, out)
+		}
+	}
+
+	out = runMain(t, "graph", "--format", "json", "--level", "layer")
+	var g depGraph
+	if err := json.Unmarshal([]byte(out), &g); err != nil {
+		t.Fatalf("expected JSON output, got %v:\n%s", err, out)
+	}
+	if len(g.Nodes) != 4 || len(g.Cycles) != 1 || strings.Join(g.Cycles[0], ",") != "domain,root" {
+		t.Fatalf("unexpected layer graph: %+v", g)
+	}
+
+	out = runMain(t, "graph", "--format", "json", "--feature", "orders")
+	g = depGraph{}
+	if err := json.Unmarshal([]byte(out), &g); err != nil {
+		t.Fatalf("expected JSON output, got %v:\n%s", err, out)
+	}
+	for _, e := range g.Edges {
+		if !strings.HasPrefix(e.From, "orders/") && !strings.HasPrefix(e.To, "orders/") {
+			t.Fatalf("expected only edges of orders, got %+v", g.Edges)
+		}
+	}
+
+	if out, code := runMainCode(t, "graph", "--feature", "billing"); code != 1 || !strings.Contains(out, "Feature billing not found") {
+		t.Fatalf("expected an unknown feature error, got code %d:\n%s", code, out)
+	}
+	if out, code := runMainCode(t, "graph", "--level", "file"); code != 1 || !strings.Contains(out, `Unknown graph level "file"`) {
+		t.Fatalf("expected an unknown level error, got code %d:\n%s", code, out)
+	}
+}
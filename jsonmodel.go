@@ -0,0 +1,315 @@
+// jsonmodel.go
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// jsonValue is a decoded JSON value that keeps object keys in source order.
+type jsonValue struct {
+	kind   string // "object", "array", "string", "number", "bool" or "null"
+	keys   []string
+	fields map[string]*jsonValue
+	items  []*jsonValue
+	str    string
+	num    json.Number
+}
+
+// inferredClass is a class inferred from the objects of a JSON sample. name is
+// snake_case, as used for file names.
+type inferredClass struct {
+	name   string
+	fields []dartField
+}
+
+// createModelFromJSON infers classes from a sample payload and generates an
+// entity and a model for each, nested classes first.
+func createModelFromJSON(feature, modelName, samplePath string) {
+	data, err := os.ReadFile(samplePath)
+	if err != nil {
+		failf("Failed to read %s: %v", samplePath, err)
+		return
+	}
+	classes, err := inferJSONClasses(modelName, data)
+	if err != nil {
+		failf("Failed to infer model from %s: %v", samplePath, err)
+		return
+	}
+	fmt.Printf("🧬 Inferred %d class(es) from %s\n", len(classes), samplePath)
+	createClasses(feature, classes)
+}
+
+// createClasses generates an entity and a model for each class. Every class
+// file counts as present while they are generated, so classes that refer to
+// each other import one another whatever the order.
+func createClasses(feature string, classes []inferredClass) {
+	for _, c := range classes {
+		pendingClassFiles[filepath.Join("lib", "features", feature, "domain", "entities", c.name+cfg.Suffixes.Entity+".dart")] = true
+		pendingClassFiles[filepath.Join("lib", "features", feature, "data", "models", c.name+cfg.Suffixes.Model+".dart")] = true
+	}
+	defer clear(pendingClassFiles)
+	for _, c := range classes {
+		createEntity(feature, c.name, c.fields)
+		createModel(feature, c.name, c.fields)
+	}
+}
+
+// inferJSONClasses infers the classes described by a JSON sample whose root is
+// an object or an array of objects. Classes are returned dependencies first,
+// so the root class comes last.
+func inferJSONClasses(rootName string, data []byte) ([]inferredClass, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	root, err := decodeJSONValue(dec)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := dec.Token(); err == nil {
+		return nil, errors.New("unexpected data after the top-level value")
+	}
+
+	var objects []*jsonValue
+	switch root.kind {
+	case "object":
+		objects = []*jsonValue{root}
+	case "array":
+		for _, item := range root.items {
+			if item.kind != "object" {
+				return nil, errors.New("a top-level array must contain only objects")
+			}
+			objects = append(objects, item)
+		}
+		if len(objects) == 0 {
+			return nil, errors.New("the top-level array is empty")
+		}
+	default:
+		return nil, errors.New("the top-level value must be an object or an array of objects")
+	}
+
+	inf := &jsonInferrer{names: classNames{}}
+	inf.inferObject(objects, snakeCase(rootName), "")
+	return inf.classes, nil
+}
+
+func decodeJSONValue(dec *json.Decoder) (*jsonValue, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch t := tok.(type) {
+	case json.Delim:
+		switch t {
+		case '{':
+			v := &jsonValue{kind: "object", fields: map[string]*jsonValue{}}
+			for dec.More() {
+				keyTok, err := dec.Token()
+				if err != nil {
+					return nil, err
+				}
+				key := keyTok.(string)
+				field, err := decodeJSONValue(dec)
+				if err != nil {
+					return nil, err
+				}
+				if _, dup := v.fields[key]; !dup {
+					v.keys = append(v.keys, key)
+				}
+				v.fields[key] = field
+			}
+			_, err := dec.Token()
+			return v, err
+		case '[':
+			v := &jsonValue{kind: "array"}
+			for dec.More() {
+				item, err := decodeJSONValue(dec)
+				if err != nil {
+					return nil, err
+				}
+				v.items = append(v.items, item)
+			}
+			_, err := dec.Token()
+			return v, err
+		}
+	case string:
+		return &jsonValue{kind: "string", str: t}, nil
+	case json.Number:
+		return &jsonValue{kind: "number", num: t}, nil
+	case bool:
+		return &jsonValue{kind: "bool"}, nil
+	case nil:
+		return &jsonValue{kind: "null"}, nil
+	}
+	return nil, fmt.Errorf("unexpected token %v", tok)
+}
+
+type jsonInferrer struct {
+	classes []inferredClass
+	names   classNames
+}
+
+// classNames tracks the snake_case class names used by one generation run.
+type classNames map[string]bool
+
+// reserve returns a unique class name, qualifying it with the parent class
+// name when it is already used.
+func (n classNames) reserve(name, parent string) string {
+	if name == "" {
+		name = "item"
+	}
+	if !n[name] {
+		n[name] = true
+		return name
+	}
+	base := name
+	if parent != "" {
+		base = parent + "_" + name
+	}
+	candidate := base
+	for i := 2; n[candidate]; i++ {
+		candidate = fmt.Sprintf("%s%d", base, i)
+	}
+	n[candidate] = true
+	return candidate
+}
+
+// inferObject merges objects into one class and returns its Dart type. Keys
+// missing from some objects, or null in any, become nullable fields.
+func (inf *jsonInferrer) inferObject(objects []*jsonValue, name, parent string) string {
+	var keys []string
+	values := map[string][]*jsonValue{}
+	for _, obj := range objects {
+		for _, key := range obj.keys {
+			if _, ok := values[key]; !ok {
+				keys = append(keys, key)
+			}
+			values[key] = append(values[key], obj.fields[key])
+		}
+	}
+	if len(keys) == 0 {
+		return "Map<String, dynamic>"
+	}
+
+	name = inf.names.reserve(name, parent)
+	fields := make([]dartField, 0, len(keys))
+	usedNames := map[string]bool{}
+	for _, key := range keys {
+		typ, nullable := inf.inferValues(values[key], snakeCase(jsonFieldName(key)), name)
+		if len(values[key]) < len(objects) {
+			nullable = true
+		}
+		if nullable || typ == "Object" {
+			typ += "?"
+		}
+		field := dartField{Name: jsonFieldName(key), Type: typ}
+		for i := 2; usedNames[field.Name]; i++ {
+			field.Name = fmt.Sprintf("%s%d", jsonFieldName(key), i)
+		}
+		usedNames[field.Name] = true
+		if field.Name != key {
+			field.Key = key
+		}
+		fields = append(fields, field)
+	}
+	inf.classes = append(inf.classes, inferredClass{name: name, fields: fields})
+	return pascalCase(name)
+}
+
+// inferValues returns the Dart type that fits every sample value and whether
+// any of them is null. Objects become classes named after name; values of
+// mixed or unknown kind become Object.
+func (inf *jsonInferrer) inferValues(values []*jsonValue, name, parent string) (string, bool) {
+	nullable := false
+	var present []*jsonValue
+	for _, v := range values {
+		if v.kind == "null" {
+			nullable = true
+			continue
+		}
+		present = append(present, v)
+	}
+	if len(present) == 0 {
+		return "Object", nullable
+	}
+
+	kind := present[0].kind
+	for _, v := range present[1:] {
+		if v.kind != kind {
+			return "Object", nullable
+		}
+	}
+	switch kind {
+	case "object":
+		return inf.inferObject(present, name, parent), nullable
+	case "array":
+		var items []*jsonValue
+		for _, v := range present {
+			items = append(items, v.items...)
+		}
+		if len(items) == 0 {
+			return "List<dynamic>", nullable
+		}
+		item, itemNullable := inf.inferValues(items, singular(name), parent)
+		if itemNullable || item == "Object" {
+			item += "?"
+		}
+		return "List<" + item + ">", nullable
+	case "number":
+		for _, v := range present {
+			if strings.ContainsAny(v.num.String(), ".eE") {
+				return "double", nullable
+			}
+		}
+		return "int", nullable
+	case "string":
+		for _, v := range present {
+			if !isJSONDateTime(v.str) {
+				return "String", nullable
+			}
+		}
+		return "DateTime", nullable
+	default:
+		return "bool", nullable
+	}
+}
+
+// isJSONDateTime reports whether s is an ISO 8601 date-time, as written by
+// DateTime.toIso8601String.
+func isJSONDateTime(s string) bool {
+	for _, layout := range []string{time.RFC3339Nano, "2006-01-02T15:04:05.999999999"} {
+		if _, err := time.Parse(layout, s); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// jsonFieldName converts a JSON key such as "created_at", "first-name" or
+// "ID" into a camelCase Dart field name.
+func jsonFieldName(key string) string {
+	var b strings.Builder
+	for _, r := range key {
+		if r < 128 && isIdentChar(byte(r)) && r != '$' {
+			b.WriteRune(r)
+		} else {
+			b.WriteRune('_')
+		}
+	}
+	name := camelCase(snakeCase(b.String()))
+	switch {
+	case name == "":
+		name = "field"
+	case name[0] >= '0' && name[0] <= '9':
+		name = "n" + name
+	}
+	if dartReservedWords[name] {
+		name += "Value"
+	}
+	return name
+}
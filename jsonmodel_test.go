@@ -0,0 +1,110 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestInferJSONClasses(t *testing.T) {
+	sample := `{
+  "id": 7,
+  "created_at": "2024-05-01T10:00:00Z",
+  "customer": {"name": "Ann", "address": {"city": "Oslo"}},
+  "line_items": [
+    {"sku": "a", "qty": 1, "price": 2},
+    {"sku": "b", "qty": 2, "price": 2.5, "note": "gift"}
+  ],
+  "tags": [],
+  "coupon": null,
+  "class": "gold",
+  "address": {"street": "Main"}
+}`
+	classes, err := inferJSONClasses("order", []byte(sample))
+	if err != nil {
+		t.Fatalf("inferJSONClasses failed: %v", err)
+	}
+
+	var names []string
+	byName := map[string][]dartField{}
+	for _, c := range classes {
+		names = append(names, c.name)
+		byName[c.name] = c.fields
+	}
+	if want := []string{"address", "customer", "line_item", "order_address", "order"}; !reflect.DeepEqual(names, want) {
+		t.Fatalf("expected classes %v, got %v", want, names)
+	}
+
+	wantOrder := []dartField{
+		{Name: "id", Type: "int"},
+		{Name: "createdAt", Type: "DateTime", Key: "created_at"},
+		{Name: "customer", Type: "Customer"},
+		{Name: "lineItems", Type: "List<LineItem>", Key: "line_items"},
+		{Name: "tags", Type: "List<dynamic>"},
+		{Name: "coupon", Type: "Object?"},
+		{Name: "classValue", Type: "String", Key: "class"},
+		{Name: "address", Type: "OrderAddress"},
+	}
+	if !reflect.DeepEqual(byName["order"], wantOrder) {
+		t.Fatalf("unexpected order fields:\n got  %+v\n want %+v", byName["order"], wantOrder)
+	}
+	wantItem := []dartField{
+		{Name: "sku", Type: "String"},
+		{Name: "qty", Type: "int"},
+		{Name: "price", Type: "double"},
+		{Name: "note", Type: "String?"},
+	}
+	if !reflect.DeepEqual(byName["line_item"], wantItem) {
+		t.Fatalf("unexpected line item fields:\n got  %+v\n want %+v", byName["line_item"], wantItem)
+	}
+}
+
+func TestInferJSONClassesRejectsScalarRoot(t *testing.T) {
+	if _, err := inferJSONClasses("order", []byte(`[1, 2]`)); err == nil {
+		t.Fatalf("expected an error for a top-level array of numbers")
+	}
+}
+
+func TestNewModelFromJSON(t *testing.T) {
+	withTempDir(t)
+	sample := `[{"id": 1, "customer": {"full_name": "Ann"}}]`
+	if err := os.WriteFile("orders.json", []byte(sample), 0644); err != nil {
+		t.Fatalf("write sample failed: %v", err)
+	}
+	_ = runMain(t, "new", "model", "shop", "order", "--from-json", "orders.json")
+
+	entity := mustReadFile(t, filepath.Join("lib", "features", "shop", "domain", "entities", "order.dart"))
+	if !strings.Contains(entity, "import 'customer.dart';") || !strings.Contains(entity, "  final Customer customer;") {
+		t.Fatalf("entity should reference the nested entity, got:\n%s", entity)
+	}
+	model := mustReadFile(t, filepath.Join("lib", "features", "shop", "data", "models", "order_model.dart"))
+	for _, expect := range []string{
+		"import '../../domain/entities/order.dart';",
+		"import 'customer_model.dart';",
+		"  final CustomerModel customer;",
+		"  Order toEntity() {",
+	} {
+		if !strings.Contains(model, expect) {
+			t.Fatalf("expected order model to contain %q, got:\n%s", expect, model)
+		}
+	}
+	nested := mustReadFile(t, filepath.Join("lib", "features", "shop", "data", "models", "customer_model.dart"))
+	for _, expect := range []string{
+		"      fullName: json['full_name'] as String,",
+		"      'full_name': fullName,",
+	} {
+		if !strings.Contains(nested, expect) {
+			t.Fatalf("expected customer model to contain %q, got:\n%s", expect, nested)
+		}
+	}
+}
+
+func TestNewModelFromJSONRejectsFieldSpecs(t *testing.T) {
+	withTempDir(t)
+	out := runMain(t, "new", "model", "shop", "order", "id:int", "--from-json", "orders.json")
+	if !strings.Contains(out, "--from-json cannot be combined with field specs") {
+		t.Fatalf("expected combination error, got:\n%s", out)
+	}
+}
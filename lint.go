@@ -0,0 +1,337 @@
+// lint.go
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Architecture layers of a feature, as laid out under lib/features/<name>/.
+const (
+	layerDomain       = "domain"
+	layerData         = "data"
+	layerPresentation = "presentation"
+)
+
+var layerNames = []string{layerDomain, layerData, layerPresentation}
+
+// Lint rule ids, as reported in JSON and SARIF output.
+const (
+	ruleLayerDependency = "layer-dependency"
+	ruleCrossFeature    = "cross-feature"
+)
+
+var lintRuleDescriptions = map[string]string{
+	ruleLayerDependency: "A layer imports a layer of its feature it must not depend on.",
+	ruleCrossFeature:    "A feature imports the internals of another feature instead of its barrel file.",
+}
+
+// Output formats of farch lint.
+const (
+	lintText  = "text"
+	lintJSON  = "json"
+	lintSARIF = "sarif"
+)
+
+// LintConfig holds the rules farch lint enforces.
+type LintConfig struct {
+	// Layers lists, per layer, the other layers of the same feature it may
+	// import. Imports within a layer are always allowed.
+	Layers map[string][]string `yaml:"layers" json:"layers"`
+	// CrossFeature lists the layers of other features a feature may import.
+	// Another feature's barrel file is always allowed.
+	CrossFeature []string `yaml:"cross_feature" json:"cross_feature"`
+}
+
+func defaultLintConfig() LintConfig {
+	return LintConfig{
+		Layers: map[string][]string{
+			layerDomain:       {},
+			layerData:         {layerDomain},
+			layerPresentation: {layerDomain},
+		},
+		CrossFeature: []string{},
+	}
+}
+
+func (l LintConfig) validate() error {
+	known := func(layer string) bool {
+		for _, name := range layerNames {
+			if name == layer {
+				return true
+			}
+		}
+		return false
+	}
+	for layer, allowed := range l.Layers {
+		if !known(layer) {
+			return fmt.Errorf("unknown lint layer %q (use domain | data | presentation)", layer)
+		}
+		for _, a := range allowed {
+			if !known(a) {
+				return fmt.Errorf("lint.layers.%s: unknown layer %q (use domain | data | presentation)", layer, a)
+			}
+		}
+	}
+	for _, a := range l.CrossFeature {
+		if !known(a) {
+			return fmt.Errorf("lint.cross_feature: unknown layer %q (use domain | data | presentation)", a)
+		}
+	}
+	return nil
+}
+
+// violation is one import breaking a lint rule.
+type violation struct {
+	Rule    string `json:"rule"`
+	File    string `json:"file"`
+	Line    int    `json:"line"`
+	Import  string `json:"import"`
+	Message string `json:"message"`
+}
+
+// dartLocation places a Dart file in the feature layout. Files outside
+// lib/features have no feature; files outside the three layers (such as the
+// feature barrel) have no layer.
+type dartLocation struct {
+	Feature string
+	Layer   string
+	Barrel  bool
+}
+
+func locateDart(path string) dartLocation {
+	parts := strings.Split(filepath.ToSlash(filepath.Clean(path)), "/")
+	if len(parts) < 4 || parts[0] != "lib" || parts[1] != "features" {
+		return dartLocation{}
+	}
+	loc := dartLocation{Feature: parts[2]}
+	if len(parts) == 4 {
+		loc.Barrel = parts[3] == parts[2]+".dart"
+		return loc
+	}
+	for _, layer := range layerNames {
+		if parts[3] == layer {
+			loc.Layer = layer
+		}
+	}
+	return loc
+}
+
+// resolveDartImport returns the project path an import of file points at, or
+// "" for SDK and third-party packages.
+func resolveDartImport(file, uri, pkg string) string {
+	switch {
+	case strings.HasPrefix(uri, "dart:"):
+		return ""
+	case strings.HasPrefix(uri, "package:"):
+		rest := strings.TrimPrefix(uri, "package:")
+		name, path, ok := strings.Cut(rest, "/")
+		if !ok || pkg == "" || name != pkg {
+			return ""
+		}
+		return filepath.Join("lib", filepath.FromSlash(path))
+	default:
+		return filepath.Join(filepath.Dir(file), filepath.FromSlash(uri))
+	}
+}
+
+// checkImport returns the rule an import from one location to another
+// breaks and why, or "" when it is allowed.
+func (l LintConfig) checkImport(from, to dartLocation) (string, string) {
+	if from.Feature == "" || to.Feature == "" {
+		return "", ""
+	}
+	if from.Feature != to.Feature {
+		if to.Barrel || containsString(l.CrossFeature, to.Layer) {
+			return "", ""
+		}
+		what := "internals"
+		if to.Layer != "" {
+			what = to.Layer + " layer"
+		}
+		return ruleCrossFeature, fmt.Sprintf("feature %s must not import the %s of feature %s; import its barrel %s.dart", from.Feature, what, to.Feature, to.Feature)
+	}
+	if from.Layer == "" || to.Layer == "" || from.Layer == to.Layer {
+		return "", ""
+	}
+	if containsString(l.Layers[from.Layer], to.Layer) {
+		return "", ""
+	}
+	return ruleLayerDependency, fmt.Sprintf("%s must not import %s", from.Layer, to.Layer)
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// dartImport is an import or export of a project file.
+type dartImport struct {
+	File   string
+	Line   int
+	URI    string
+	Target string
+}
+
+// projectImports lists the imports and exports of the Dart files under root
+// that point at files of the project, and the number of files read.
+func projectImports(root string) ([]dartImport, int, error) {
+	if _, err := os.Stat(root); os.IsNotExist(err) {
+		return nil, 0, nil
+	}
+	pkg := packageName()
+	var imports []dartImport
+	files := 0
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !strings.HasSuffix(path, ".dart") || isGeneratedOutput(path) {
+			return nil
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		files++
+		for _, d := range parseDirectives(string(data)) {
+			if d.Keyword != dirImport && d.Keyword != dirExport {
+				continue
+			}
+			for _, u := range d.URIs {
+				if target := resolveDartImport(path, u.Value, pkg); target != "" {
+					imports = append(imports, dartImport{File: path, Line: d.Line, URI: u.Value, Target: target})
+				}
+			}
+		}
+		return nil
+	})
+	return imports, files, err
+}
+
+// lintFeatures checks every import and export of the Dart files under
+// lib/features. It returns the violations and the number of files checked.
+func lintFeatures(rules LintConfig) ([]violation, int, error) {
+	imports, files, err := projectImports(filepath.Join("lib", "features"))
+	if err != nil {
+		return nil, files, err
+	}
+	var found []violation
+	for _, imp := range imports {
+		rule, msg := rules.checkImport(locateDart(imp.File), locateDart(imp.Target))
+		if rule == "" {
+			continue
+		}
+		found = append(found, violation{
+			Rule:    rule,
+			File:    filepath.ToSlash(imp.File),
+			Line:    imp.Line,
+			Import:  imp.URI,
+			Message: msg,
+		})
+	}
+	sort.SliceStable(found, func(i, j int) bool {
+		if found[i].File != found[j].File {
+			return found[i].File < found[j].File
+		}
+		return found[i].Line < found[j].Line
+	})
+	return found, files, nil
+}
+
+// runLint runs farch lint and returns the exit code: 1 when any import breaks
+// the rules, so CI fails.
+func runLint(format string) int {
+	if format == "" {
+		format = lintText
+	}
+	if format != lintText && format != lintJSON && format != lintSARIF {
+		fmt.Printf("❌ Unknown lint format %q (use text | json | sarif)\n", format)
+		return 1
+	}
+	found, files, err := lintFeatures(cfg.Lint)
+	if err != nil {
+		fmt.Printf("❌ Failed to lint: %v\n", err)
+		return 1
+	}
+
+	switch format {
+	case lintJSON:
+		out := struct {
+			Files      int         `json:"files"`
+			Violations []violation `json:"violations"`
+		}{files, found}
+		if out.Violations == nil {
+			out.Violations = []violation{}
+		}
+		printJSON(out)
+	case lintSARIF:
+		printJSON(sarifLog(found))
+	default:
+		for _, v := range found {
+			fmt.Printf("❌ %s:%d %s (import '%s') [%s]\n", v.File, v.Line, v.Message, v.Import, v.Rule)
+		}
+		if len(found) == 0 {
+			fmt.Printf("✅ No architecture violations in %d file(s)\n", files)
+		} else {
+			fmt.Printf("🧹 %d architecture violation(s) in %d file(s) checked\n", len(found), files)
+		}
+	}
+	if len(found) > 0 {
+		return 1
+	}
+	return 0
+}
+
+func printJSON(v any) {
+	data, _ := json.MarshalIndent(v, "", "  ")
+	fmt.Println(string(data))
+}
+
+// sarifLog renders the violations as a SARIF 2.1.0 log, as code scanning
+// tools ingest.
+func sarifLog(found []violation) map[string]any {
+	ids := make([]string, 0, len(lintRuleDescriptions))
+	for id := range lintRuleDescriptions {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	rules := make([]map[string]any, len(ids))
+	for i, id := range ids {
+		rules[i] = map[string]any{
+			"id":               id,
+			"shortDescription": map[string]any{"text": lintRuleDescriptions[id]},
+		}
+	}
+	results := make([]map[string]any, len(found))
+	for i, v := range found {
+		results[i] = map[string]any{
+			"ruleId":  v.Rule,
+			"level":   "error",
+			"message": map[string]any{"text": fmt.Sprintf("%s (import '%s')", v.Message, v.Import)},
+			"locations": []map[string]any{{
+				"physicalLocation": map[string]any{
+					"artifactLocation": map[string]any{"uri": v.File},
+					"region":           map[string]any{"startLine": v.Line},
+				},
+			}},
+		}
+	}
+	return map[string]any{
+		"$schema": "https://json.schemastore.org/sarif-2.1.0.json",
+		"version": "2.1.0",
+		"runs": []map[string]any{{
+			"tool":    map[string]any{"driver": map[string]any{"name": "farch", "rules": rules}},
+			"results": results,
+		}},
+	}
+}
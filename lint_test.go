@@ -0,0 +1,103 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func mustWriteFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLintReportsLayerViolations(t *testing.T) {
+	withTempDir(t)
+	mustWriteFile(t, "pubspec.yaml", "name: shop\n")
+	_ = runMain(t, "new", "feature", "orders")
+	_ = runMain(t, "new", "feature", "cart")
+
+	if out, code := runMainCode(t, "lint"); code != 0 || !strings.Contains(out, "✅ No architecture violations") {
+		t.Fatalf("expected a generated project to pass lint, got code %d:\n%s", code, out)
+	}
+
+	mustWriteFile(t, filepath.Join("lib", "features", "orders", "domain", "usecases", "sync.dart"),
+		"import 'package:dio/dio.dart';\nimport '../../data/repositories/orders_repository_impl.dart';\n")
+	mustWriteFile(t, filepath.Join("lib", "features", "orders", "presentation", "pages", "list_page.dart"),
+		"import 'package:shop/features/orders/data/datasources/remote_datasource.dart';\nimport 'package:shop/core/page_names.dart';\n")
+	mustWriteFile(t, filepath.Join("lib", "features", "cart", "presentation", "widgets", "total.dart"),
+		"import '../../../orders/domain/entities/orders.dart';\nimport '../../../orders/orders.dart';\n")
+
+	out, code := runMainCode(t, "lint")
+	if code != 1 {
+		t.Fatalf("expected lint to exit 1, got %d:\n%s", code, out)
+	}
+	for _, expect := range []string{
+		"❌ lib/features/cart/presentation/widgets/total.dart:1 feature cart must not import the domain layer of feature orders; import its barrel orders.dart (import '../../../orders/domain/entities/orders.dart') [cross-feature]",
+		"❌ lib/features/orders/domain/usecases/sync.dart:2 domain must not import data (import '../../data/repositories/orders_repository_impl.dart') [layer-dependency]",
+		"❌ lib/features/orders/presentation/pages/list_page.dart:1 presentation must not import data",
+		"🧹 3 architecture violation(s)",
+	} {
+		if !strings.Contains(out, expect) {
+			t.Fatalf("expected %q, got:\n%s", expect, out)
+		}
+	}
+
+	out, _ = runMainCode(t, "lint", "--format", "json")
+	var report struct {
+		Violations []violation `json:"violations"`
+	}
+	if err := json.Unmarshal([]byte(out), &report); err != nil {
+		t.Fatalf("expected JSON output, got %v:\n%s", err, out)
+	}
+	if len(report.Violations) != 3 || report.Violations[1].Rule != ruleLayerDependency || report.Violations[1].Line != 2 {
+		t.Fatalf("unexpected violations: %+v", report.Violations)
+	}
+
+	out, _ = runMainCode(t, "lint", "--format=sarif")
+	var sarif struct {
+		Version string `json:"version"`
+		Runs    []struct {
+			Results []struct {
+				RuleID    string `json:"ruleId"`
+				Locations []struct {
+					PhysicalLocation struct {
+						ArtifactLocation struct {
+							URI string `json:"uri"`
+						} `json:"artifactLocation"`
+					} `json:"physicalLocation"`
+				} `json:"locations"`
+			} `json:"results"`
+		} `json:"runs"`
+	}
+	if err := json.Unmarshal([]byte(out), &sarif); err != nil {
+		t.Fatalf("expected SARIF output, got %v:\n%s", err, out)
+	}
+	if sarif.Version != "2.1.0" || len(sarif.Runs) != 1 || len(sarif.Runs[0].Results) != 3 ||
+		sarif.Runs[0].Results[0].Locations[0].PhysicalLocation.ArtifactLocation.URI != "lib/features/cart/presentation/widgets/total.dart" {
+		t.Fatalf("unexpected SARIF log:\n%s", out)
+	}
+}
+
+func TestLintHonorsConfiguredRules(t *testing.T) {
+	withTempDir(t)
+	mustWriteFile(t, ".farch.yaml", "lint:\n  layers:\n    presentation: [domain, data]\n  cross_feature: [domain]\n")
+	mustWriteFile(t, filepath.Join("lib", "features", "orders", "presentation", "pages", "list_page.dart"),
+		"import '../../data/datasources/remote_datasource.dart';\n")
+	mustWriteFile(t, filepath.Join("lib", "features", "cart", "domain", "entities", "cart.dart"),
+		"import '../../../orders/domain/entities/orders.dart';\n")
+
+	if out, code := runMainCode(t, "lint"); code != 0 {
+		t.Fatalf("expected the configured rules to allow the imports, got code %d:\n%s", code, out)
+	}
+	if out, code := runMainCode(t, "lint", "--format", "xml"); code != 1 || !strings.Contains(out, `Unknown lint format "xml"`) {
+		t.Fatalf("expected an unknown format error, got code %d:\n%s", code, out)
+	}
+}
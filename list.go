@@ -0,0 +1,256 @@
+// list.go
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+// featureInventory is what a feature contains, by kind of generated file.
+type featureInventory struct {
+	Name         string   `json:"name"`
+	Entities     []string `json:"entities"`
+	Usecases     []string `json:"usecases"`
+	Repositories []string `json:"repositories"`
+	Datasources  []string `json:"datasources"`
+	Providers    []string `json:"providers"`
+	Pages        []string `json:"pages"`
+	Widgets      []string `json:"widgets"`
+}
+
+// routeInventory is a page_names.dart constant and the page routed to it.
+// Constants no page owns have no feature or page.
+type routeInventory struct {
+	Constant string `json:"constant"`
+	Path     string `json:"path"`
+	Feature  string `json:"feature,omitempty"`
+	Page     string `json:"page,omitempty"`
+	Routed   bool   `json:"routed"`
+}
+
+type providerInventory struct {
+	Feature string `json:"feature"`
+	Name    string `json:"name"`
+	Kind    string `json:"kind"`
+	File    string `json:"file"`
+}
+
+type usecaseInventory struct {
+	Feature string `json:"feature"`
+	Name    string `json:"name"`
+	File    string `json:"file"`
+}
+
+// dartNames returns the names of the Dart files in dir ending in suffix,
+// without it. Generated outputs are left out.
+func dartNames(dir, suffix string) []string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return []string{}
+	}
+	names := []string{}
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasSuffix(name, suffix+".dart") || isGeneratedOutput(name) {
+			continue
+		}
+		names = append(names, strings.TrimSuffix(name, suffix+".dart"))
+	}
+	sort.Strings(names)
+	return names
+}
+
+// listFeatures returns the features under lib/features, sorted by name.
+func listFeatures() []string {
+	entries, err := os.ReadDir(filepath.Join("lib", "features"))
+	if err != nil {
+		return nil
+	}
+	var features []string
+	for _, e := range entries {
+		if e.IsDir() {
+			features = append(features, e.Name())
+		}
+	}
+	return features
+}
+
+func inventoryOf(feature string) featureInventory {
+	dir := filepath.Join("lib", "features", feature)
+	inv := featureInventory{
+		Name:         feature,
+		Entities:     dartNames(filepath.Join(dir, "domain", "entities"), cfg.Suffixes.Entity),
+		Usecases:     dartNames(filepath.Join(dir, "domain", "usecases"), cfg.Suffixes.Usecase),
+		Repositories: dartNames(filepath.Join(dir, "domain", "repositories"), cfg.Suffixes.Repository),
+		Datasources:  dartNames(filepath.Join(dir, "data", "datasources"), cfg.Suffixes.Datasource),
+		Pages:        dartNames(filepath.Join(dir, "presentation", "pages"), cfg.Suffixes.Page),
+		Widgets:      dartNames(filepath.Join(dir, "presentation", "widgets"), cfg.Suffixes.Widget),
+	}
+	inv.Providers = []string{}
+	for _, p := range listProviders(feature) {
+		inv.Providers = append(inv.Providers, p.Name)
+	}
+	return inv
+}
+
+// listProviders returns the Riverpod providers, cubits and blocs of a feature.
+func listProviders(feature string) []providerInventory {
+	var found []providerInventory
+	dir := filepath.Join("lib", "features", feature, "presentation", "providers")
+	for _, name := range dartNames(dir, cfg.Suffixes.Provider) {
+		file := filepath.Join(dir, name+cfg.Suffixes.Provider+".dart")
+		kind := providerFunctional
+		if data, err := os.ReadFile(file); err == nil {
+			kind = providerKindOf(string(data))
+		}
+		found = append(found, providerInventory{Feature: feature, Name: name, Kind: kind, File: filepath.ToSlash(file)})
+	}
+	for _, kind := range []string{blocCubit, blocBloc} {
+		for _, name := range dartNames(blocDir(feature), "_"+kind) {
+			found = append(found, providerInventory{Feature: feature, Name: name, Kind: kind, File: filepath.ToSlash(blocFile(feature, name, kind))})
+		}
+	}
+	sort.SliceStable(found, func(i, j int) bool { return found[i].Name < found[j].Name })
+	return found
+}
+
+// listRoutes joins the constants of page_names.dart with the pages they name
+// and whether router.dart routes them.
+func listRoutes() []routeInventory {
+	routes := []routeInventory{}
+	data, err := os.ReadFile(cfg.Paths.PageNames)
+	if err != nil {
+		return routes
+	}
+	var router string
+	if content, err := os.ReadFile(cfg.Paths.Router); err == nil {
+		router = string(content)
+	}
+	owners := map[string][2]string{}
+	for _, feature := range listFeatures() {
+		for _, page := range featurePages(feature) {
+			owners[pageConstName(feature, page)] = [2]string{feature, page}
+		}
+	}
+	for _, m := range pageConstRe.FindAllStringSubmatch(string(data), -1) {
+		r := routeInventory{Constant: m[1], Path: m[2]}
+		if owner, ok := owners[r.Constant]; ok {
+			r.Feature, r.Page = owner[0], owner[1]
+			r.Routed = hasPageRoute(router, r.Feature, r.Page)
+		}
+		routes = append(routes, r)
+	}
+	return routes
+}
+
+// runList prints the project inventory of one kind and returns the exit code.
+func runList(what string, asJSON bool) int {
+	if what == "" {
+		what = "features"
+	}
+	features := listFeatures()
+	var rows [][]string
+	var header []string
+	var out any
+	switch what {
+	case "features":
+		inventory := []featureInventory{}
+		header = []string{"FEATURE", "ENTITIES", "USECASES", "REPOSITORIES", "DATASOURCES", "PROVIDERS", "PAGES", "WIDGETS"}
+		for _, f := range features {
+			inv := inventoryOf(f)
+			inventory = append(inventory, inv)
+			rows = append(rows, []string{f, joinNames(inv.Entities), joinNames(inv.Usecases), joinNames(inv.Repositories),
+				joinNames(inv.Datasources), joinNames(inv.Providers), joinNames(inv.Pages), joinNames(inv.Widgets)})
+		}
+		out = inventory
+	case "pages":
+		type pageRow struct {
+			Feature  string `json:"feature"`
+			Name     string `json:"name"`
+			Constant string `json:"constant"`
+			Path     string `json:"path"`
+			File     string `json:"file"`
+		}
+		pages := []pageRow{}
+		header = []string{"FEATURE", "PAGE", "CONSTANT", "PATH"}
+		for _, f := range features {
+			for _, p := range featurePages(f) {
+				constName := pageConstName(f, p)
+				path, _ := pageConstValue(constName)
+				pages = append(pages, pageRow{f, p, constName, path, filepath.ToSlash(pageFilePath(f, p))})
+				rows = append(rows, []string{f, p, constName, path})
+			}
+		}
+		out = pages
+	case "routes":
+		routes := listRoutes()
+		header = []string{"CONSTANT", "PATH", "FEATURE", "PAGE", "ROUTED"}
+		for _, r := range routes {
+			routed := "no"
+			if r.Routed {
+				routed = "yes"
+			}
+			rows = append(rows, []string{r.Constant, r.Path, orDash(r.Feature), orDash(r.Page), routed})
+		}
+		out = routes
+	case "providers":
+		providers := []providerInventory{}
+		header = []string{"FEATURE", "PROVIDER", "KIND", "FILE"}
+		for _, f := range features {
+			for _, p := range listProviders(f) {
+				providers = append(providers, p)
+				rows = append(rows, []string{f, p.Name, p.Kind, p.File})
+			}
+		}
+		out = providers
+	case "usecases":
+		usecases := []usecaseInventory{}
+		header = []string{"FEATURE", "USECASE", "FILE"}
+		for _, f := range features {
+			dir := filepath.Join("lib", "features", f, "domain", "usecases")
+			for _, u := range dartNames(dir, cfg.Suffixes.Usecase) {
+				file := filepath.ToSlash(filepath.Join(dir, u+cfg.Suffixes.Usecase+".dart"))
+				usecases = append(usecases, usecaseInventory{f, u, file})
+				rows = append(rows, []string{f, u, file})
+			}
+		}
+		out = usecases
+	default:
+		fmt.Printf("❌ Unknown list kind %q (use features | pages | routes | providers | usecases)\n", what)
+		return 1
+	}
+
+	if asJSON {
+		printJSON(out)
+		return 0
+	}
+	if len(rows) == 0 {
+		fmt.Printf("📭 No %s found\n", what)
+		return 0
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, strings.Join(header, "\t"))
+	for _, row := range rows {
+		fmt.Fprintln(w, strings.Join(row, "\t"))
+	}
+	w.Flush()
+	return 0
+}
+
+func joinNames(names []string) string {
+	if len(names) == 0 {
+		return "-"
+	}
+	return strings.Join(names, ",")
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestListFeaturesAsJSON(t *testing.T) {
+	withTempDir(t)
+	_ = runMain(t, "new", "feature", "orders")
+	_ = runMain(t, "new", "widget", "orders", "badge")
+	_ = runMain(t, "new", "provider", "orders", "counter", "--kind", "notifier")
+
+	var inventory []featureInventory
+	out := runMain(t, "list", "--json")
+	if err := json.Unmarshal([]byte(out), &inventory); err != nil {
+		t.Fatalf("expected JSON output, got %v:\n%s", err, out)
+	}
+	if len(inventory) != 1 {
+		t.Fatalf("expected one feature, got %+v", inventory)
+	}
+	got := inventory[0]
+	if got.Name != "orders" || strings.Join(got.Entities, ",") != "orders" || strings.Join(got.Usecases, ",") != "example" ||
+		strings.Join(got.Repositories, ",") != "orders" || strings.Join(got.Datasources, ",") != "remote" ||
+		strings.Join(got.Providers, ",") != "counter,orders" || strings.Join(got.Pages, ",") != "orders" ||
+		strings.Join(got.Widgets, ",") != "badge" {
+		t.Fatalf("unexpected inventory: %+v", got)
+	}
+
+	out = runMain(t, "inspect", "providers")
+	if !strings.Contains(out, "FEATURE  PROVIDER  KIND        FILE\n") ||
+		!strings.Contains(out, "orders   counter   notifier    lib/features/orders/presentation/providers/counter_provider.dart\n") {
+		t.Fatalf("expected a providers table, got:\n%s", out)
+	}
+}
+
+func TestListRoutes(t *testing.T) {
+	withTempDir(t)
+	_ = runMain(t, "new", "page", "orders", "detail", "--path-param", "id:int")
+	names := filepath.Join("lib", "core", "page_names.dart")
+	mustWriteFile(t, names, mustReadFile(t, names)+"const kLegacyPage = '/legacy';\n")
+
+	var routes []routeInventory
+	out := runMain(t, "list", "routes", "--json")
+	if err := json.Unmarshal([]byte(out), &routes); err != nil {
+		t.Fatalf("expected JSON output, got %v:\n%s", err, out)
+	}
+	want := []routeInventory{
+		{Constant: "kDetailPage", Path: "/detail/:id", Feature: "orders", Page: "detail", Routed: true},
+		{Constant: "kLegacyPage", Path: "/legacy"},
+	}
+	if len(routes) != len(want) || routes[0] != want[0] || routes[1] != want[1] {
+		t.Fatalf("expected %+v, got %+v", want, routes)
+	}
+
+	out = runMain(t, "list", "routes")
+	if !strings.Contains(out, "kLegacyPage  /legacy      -        -       no\n") {
+		t.Fatalf("expected a routes table, got:\n%s", out)
+	}
+	if out, code := runMainCode(t, "list", "models"); code != 1 || !strings.Contains(out, `Unknown list kind "models"`) {
+		t.Fatalf("expected an unknown kind error, got code %d:\n%s", code, out)
+	}
+}
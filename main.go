@@ -35,6 +35,7 @@ var baseStructure = []string{
 	"lib/features/%s/presentation/widgets",
 	"test/features/%s",
 	"test/features/%s/data/datasources",
+	"test/features/%s/data/models",
 	"test/features/%s/data/repositories",
 	"test/features/%s/domain/entities",
 	"test/features/%s/domain/usecases",
@@ -294,402 +295,336 @@ func pascalCase(s string) string {
 	return strings.Join(parts, "")
 }
 
-func insertImportDirective(content, importLine string) string {
-	if strings.Contains(content, importLine) {
-		return content
-	}
-
-	lines := strings.Split(content, "\n")
-	insertAt := 0
-	lastImport := -1
-	firstPart := -1
-	libraryLine := -1
-
-	for i, line := range lines {
-		trimmed := strings.TrimSpace(line)
-		if strings.HasPrefix(trimmed, "library ") {
-			libraryLine = i
-		}
-		if strings.HasPrefix(trimmed, "import ") {
-			lastImport = i
-		}
-		if firstPart == -1 && strings.HasPrefix(trimmed, "part ") {
-			firstPart = i
-		}
-	}
-
-	switch {
-	case lastImport != -1:
-		insertAt = lastImport + 1
-	case firstPart != -1:
-		insertAt = firstPart
-	case libraryLine != -1:
-		insertAt = libraryLine + 1
-	default:
-		insertAt = 0
-	}
-
-	newLines := make([]string, 0, len(lines)+1)
-	newLines = append(newLines, lines[:insertAt]...)
-	newLines = append(newLines, strings.TrimRight(importLine, "\n"))
-	newLines = append(newLines, lines[insertAt:]...)
-	return strings.Join(newLines, "\n")
-}
-
-func normalizeImportPartOrder(content string) string {
-	lines := strings.Split(content, "\n")
-	firstPart := -1
-	for i, line := range lines {
-		if strings.HasPrefix(strings.TrimSpace(line), "part ") {
-			firstPart = i
-			break
-		}
-	}
-	if firstPart == -1 {
-		return content
-	}
-
-	misplacedImports := make([]string, 0)
-	filtered := make([]string, 0, len(lines))
-	for i, line := range lines {
-		trimmed := strings.TrimSpace(line)
-		if i > firstPart && strings.HasPrefix(trimmed, "import ") {
-			misplacedImports = append(misplacedImports, line)
-			continue
-		}
-		filtered = append(filtered, line)
-	}
-	if len(misplacedImports) == 0 {
-		return content
-	}
-
-	firstPartFiltered := -1
-	lastImportBeforePart := -1
-	for i, line := range filtered {
-		trimmed := strings.TrimSpace(line)
-		if strings.HasPrefix(trimmed, "part ") && firstPartFiltered == -1 {
-			firstPartFiltered = i
-		}
-		if firstPartFiltered == -1 && strings.HasPrefix(trimmed, "import ") {
-			lastImportBeforePart = i
-		}
-	}
-	if firstPartFiltered == -1 {
-		return content
-	}
-
-	insertAt := firstPartFiltered
-	if lastImportBeforePart != -1 {
-		insertAt = lastImportBeforePart + 1
+// appendRoute ensures router.dart exists, adds an import for the page and injects a GoRoute.
+// The route goes under the parent page's route when parent is set, else into
+// the feature's shell when it has one, else at the top level.
+func appendRoute(feature, pageName string, params []routeParam, parent string) {
+	routerFile := cfg.Paths.Router
+	if !ensureRouter() {
+		return
 	}
 
-	out := make([]string, 0, len(filtered)+len(misplacedImports))
-	out = append(out, filtered[:insertAt]...)
-	out = append(out, misplacedImports...)
-	out = append(out, filtered[insertAt:]...)
-	return strings.Join(out, "\n")
-}
-
-// appendRoute ensures router.dart exists, adds an import for the page and injects a GoRoute
-func appendRoute(feature, pageName string) {
-	routerFile := filepath.Join("lib", "core", "router.dart")
-
-	// ensure folder exists
-	_ = os.MkdirAll(filepath.Dir(routerFile), os.ModePerm)
-
 	// import page
-	importLine := fmt.Sprintf("import '../features/%s/presentation/pages/%s_page.dart';\n", feature, pageName)
+	pageFile := pageFilePath(feature, pageName)
+	importLine := pageRouterImport(feature, pageName) + "\n"
 	// import page_names.dart constants
-	constImport := "import 'page_names.dart';\n"
-
-	// route constant
-	constName := "k" + pascalCase(pageName) + "Page"
-
-	routeLine := fmt.Sprintf("    GoRoute(path: %s, builder: (context, state) => const %sPage()),\n", constName, pascalCase(pageName))
-
-	// create base router if doesn't exist
-	if _, err := os.Stat(routerFile); os.IsNotExist(err) {
-		base := `import 'package:flutter/material.dart';
-import 'package:go_router/go_router.dart';
-import 'package:riverpod_annotation/riverpod_annotation.dart';
-part 'router.g.dart';
-
-// REQUIRED_FOR_FARCH: Do not remove this marker. farch inserts page imports above this line.
-// AUTO_IMPORTS
-
-@riverpod
-Raw<GoRouter> router(Ref ref) => GoRouter(
-  routes: [
-    // REQUIRED_FOR_FARCH: Do not remove this marker. farch inserts new GoRoute entries above this line.
-    // AUTO_ROUTES
-  ],
-);
-`
-		if err := os.WriteFile(routerFile, []byte(base), 0644); err != nil {
-			fmt.Printf("❌ Failed to create %s: %v\n", routerFile, err)
-			return
-		}
-		fmt.Println("📝 Created lib/core/router.dart")
+	constImport := fmt.Sprintf("import '%s';\n", dartRelImport(routerFile, cfg.Paths.PageNames))
+
+	// route constant; nested routes take a path relative to their parent
+	constName := pageConstName(feature, pageName)
+	path := constName
+	if parent != "" {
+		path = "'" + strings.TrimPrefix(routePath(pageName, params), "/") + "'"
 	}
+	route := fmt.Sprintf("GoRoute(path: %s, builder: (context, state) => %s),", path, routeBuilder(pageRouteClass(feature, pageName), params))
+	routeLine := "    " + route + "\n"
 
 	// read file
-	data, err := os.ReadFile(routerFile)
+	data, err := changes.readFile(routerFile)
 	if err != nil {
-		fmt.Printf("❌ Failed to read %s: %v\n", routerFile, err)
+		failf("Failed to read %s: %v", routerFile, err)
 		return
 	}
 	content := string(data)
 	content = normalizeImportPartOrder(content)
 
 	// add import for page_names.dart if not present
-	if !strings.Contains(content, constImport) {
+	if !hasDirective(content, constImport) {
 		if !strings.Contains(content, "// AUTO_IMPORTS") {
 			fmt.Println("⚠️  router.dart is missing // AUTO_IMPORTS marker; using fallback import insertion.")
 		}
 		content = insertImportDirective(content, constImport)
-		fmt.Println("➡️  Added import for page_names.dart")
+		fmt.Printf("➡️  Added import for %s\n", filepath.Base(cfg.Paths.PageNames))
 	}
 
 	// add import for page if not present
-	if !strings.Contains(content, importLine) {
+	if !hasDirective(content, importLine) {
 		if !strings.Contains(content, "// AUTO_IMPORTS") {
 			fmt.Println("⚠️  router.dart is missing // AUTO_IMPORTS marker; using fallback import insertion.")
 		}
 		content = insertImportDirective(content, importLine)
-		fmt.Printf("➡️  Added import for %s_page.dart\n", pageName)
+		fmt.Printf("➡️  Added import for %s\n", filepath.Base(pageFile))
 	}
 
 	// add route if not present
-	if !strings.Contains(content, routeLine) {
-		if strings.Contains(content, "// AUTO_ROUTES") {
-			content = strings.Replace(content, "// AUTO_ROUTES", routeLine+"    // AUTO_ROUTES", 1)
-		} else if strings.Contains(content, "routes: [") {
-			fmt.Println("⚠️  router.dart is missing // AUTO_ROUTES marker; inserting route at start of routes list.")
-			content = strings.Replace(content, "routes: [", "routes: [\n"+routeLine, 1)
+	if !hasPageRoute(content, feature, pageName) {
+		if parent != "" {
+			updated, err := nestRoute(content, feature, parent, route)
+			if err != nil {
+				failf("Cannot nest %sPage: %v", pascalCase(pageName), err)
+				return
+			}
+			content = updated
+			fmt.Printf("➡️  Added GoRoute for %sPage under %sPage (path: %s)\n", pascalCase(pageName), pascalCase(parent), path)
+		} else if updated, ok := addShellRoute(content, feature, route); ok {
+			content = updated
+			fmt.Printf("➡️  Added GoRoute for %sPage to %s (path: %s)\n", pascalCase(pageName), shellClass(feature), constName)
 		} else {
-			fmt.Println("⚠️  router.dart structure is non-standard; appending fallback GoRouter block.")
-			idx := strings.LastIndex(content, ");")
-			if idx != -1 {
-				block := "\nfinal GoRouter router = GoRouter(\n  routes: [\n" + routeLine + "  ],\n);\n"
-				content = content[:idx] + block + content[idx:]
+			if updated, ok := insertAboveMarker(content, routerMarker, route); ok {
+				content = updated
+			} else if strings.Contains(content, "routes: [") {
+				fmt.Println("⚠️  router.dart is missing // AUTO_ROUTES marker; inserting route at start of routes list.")
+				content = strings.Replace(content, "routes: [", "routes: [\n"+routeLine, 1)
 			} else {
-				content = content + "\nfinal GoRouter router = GoRouter(\n  routes: [\n" + routeLine + "  ],\n);\n"
+				fmt.Println("⚠️  router.dart structure is non-standard; appending fallback GoRouter block.")
+				idx := strings.LastIndex(content, ");")
+				if idx != -1 {
+					block := "\nfinal GoRouter router = GoRouter(\n  routes: [\n" + routeLine + "  ],\n);\n"
+					content = content[:idx] + block + content[idx:]
+				} else {
+					content = content + "\nfinal GoRouter router = GoRouter(\n  routes: [\n" + routeLine + "  ],\n);\n"
+				}
 			}
+			fmt.Printf("➡️  Added GoRoute for %sPage (path: %s)\n", pascalCase(pageName), constName)
 		}
-		fmt.Printf("➡️  Added GoRoute for %sPage (path: %s)\n", pascalCase(pageName), constName)
 	}
 
 	// write back
-	if err := os.WriteFile(routerFile, []byte(content), 0644); err != nil {
-		fmt.Printf("❌ Failed to update %s: %v\n", routerFile, err)
+	if err := changes.writeFile(routerFile, []byte(content)); err != nil {
+		failf("Failed to update %s: %v", routerFile, err)
 		return
 	}
 }
 
 // createFeature scaffolds the whole feature folders and some default files
 func createFeature(feature string) {
-	for _, pattern := range baseStructure {
+	createFeatureExcept(feature, nil)
+}
+
+// createFeatureExcept lays out a feature with its default scaffolds, leaving
+// out the scaffold kinds in skip.
+func createFeatureExcept(feature string, skip map[string]bool) {
+	for _, pattern := range cfg.Structure {
 		var dirPath string
 		if strings.Contains(pattern, "%s") {
 			dirPath = fmt.Sprintf(pattern, feature)
 		} else {
 			dirPath = pattern
 		}
-		_ = os.MkdirAll(dirPath, os.ModePerm)
-		fmt.Printf("✅ Created %s\n", dirPath)
+		if cfg.StateManagement == stateBloc && filepath.Base(dirPath) == "providers" {
+			dirPath = filepath.Join(filepath.Dir(dirPath), "bloc")
+		}
+		changes.mkdirAll(dirPath)
+		changes.createdf("✅ Created %s\n", dirPath)
 	}
 	// default scaffolds
-	createEntity(feature, feature)
-	createUsecase(feature, "example")
-	createRepository(feature, feature)
-	createDatasource(feature, "remote")
-	createProvider(feature, feature)
-	createPage(feature, feature)
+	for _, scaffold := range cfg.Scaffolds {
+		if skip[scaffold.Kind] {
+			continue
+		}
+		name := scaffold.Name
+		if strings.Contains(name, "%s") {
+			name = fmt.Sprintf(name, feature)
+		}
+		createScaffold(scaffold.Kind, feature, name)
+	}
+
+	ensureInjectionContainer()
+}
 
-	rootFile := filepath.Join("lib", "injection_container.dart")
-	if _, err := os.Stat(rootFile); os.IsNotExist(err) {
-		if err := os.WriteFile(rootFile, []byte("// Dependency injection setup\n"), 0644); err == nil {
-			fmt.Println("📝 Created lib/injection_container.dart")
+// createScaffold dispatches one configured default scaffold to its generator.
+func createScaffold(kind, feature, name string) {
+	switch kind {
+	case "entity":
+		createEntity(feature, name, nil)
+	case "usecase":
+		createUsecase(feature, name)
+	case "repository":
+		createRepository(feature, name)
+	case "datasource":
+		createDatasource(feature, name)
+	case "model":
+		createModel(feature, name, nil)
+	case "provider":
+		if cfg.StateManagement == stateBloc {
+			createBloc(feature, name, blocCubit)
+			return
 		}
+		createProvider(feature, name, providerFunctional, "")
+	case "page":
+		createPage(feature, name, nil, "")
 	}
 }
 
-func createEntity(feature, entityName string) {
+func createEntity(feature, entityName string, fields []dartField) {
 	dir := filepath.Join("lib", "features", feature, "domain", "entities")
-	_ = os.MkdirAll(dir, os.ModePerm)
-
-	file := filepath.Join(dir, entityName+".dart")
-	content := fmt.Sprintf(`class %s {
-  final int id;
-  %s(this.id);
-}
-`, pascalCase(entityName), pascalCase(entityName))
+	changes.mkdirAll(dir)
 
-	writeFile(file, content)
+	file := filepath.Join(dir, entityName+cfg.Suffixes.Entity+".dart")
+	if len(fields) == 0 {
+		fields = defaultEntityFields
+	}
+	writeTemplate(file, "entity.dart.tmpl", templateData{
+		Feature:         feature,
+		Name:            entityName,
+		Fields:          fields,
+		NeedsFoundation: needsFoundation(fields),
+		Imports: classImports(file, fields, func(class string) string {
+			return filepath.Join(dir, snakeCase(class)+cfg.Suffixes.Entity+".dart")
+		}),
+	})
 	writeTest(feature, filepath.Join("domain", "entities", entityName+"_entity_test.dart"), testStub("entity "+entityName))
 }
 
 func createUsecase(feature, usecaseName string) {
 	dir := filepath.Join("lib", "features", feature, "domain", "usecases")
-	_ = os.MkdirAll(dir, os.ModePerm)
-
-	file := filepath.Join(dir, usecaseName+".dart")
-	content := fmt.Sprintf(`class %s {
-  Future<void> call() async {
-    // TODO: implement usecase
-  }
-}
-`, pascalCase(usecaseName))
-
-	writeFile(file, content)
+	changes.mkdirAll(dir)
+
+	file := filepath.Join(dir, usecaseName+cfg.Suffixes.Usecase+".dart")
+	ensureCore()
+	writeTemplate(file, "usecase.dart.tmpl", templateData{
+		Feature: feature,
+		Name:    usecaseName,
+		Imports: coreImports(file, coreUsecaseFile, coreResultFile),
+	})
 	writeTest(feature, filepath.Join("domain", "usecases", usecaseName+"_usecase_test.dart"), testStub("usecase "+usecaseName))
+	registerUsecase(feature, usecaseName, file, "")
 }
 
 func createRepository(feature, repoName string) {
 	domainDir := filepath.Join("lib", "features", feature, "domain", "repositories")
 	dataDir := filepath.Join("lib", "features", feature, "data", "repositories")
 
-	_ = os.MkdirAll(domainDir, os.ModePerm)
-	_ = os.MkdirAll(dataDir, os.ModePerm)
-
-	domainFile := filepath.Join(domainDir, repoName+"_repository.dart")
-	dataFile := filepath.Join(dataDir, repoName+"_repository_impl.dart")
-
-	domainContent := fmt.Sprintf(`abstract class %sRepository {
-  // TODO: define repository methods
-}
-`, pascalCase(repoName))
-
-	dataContent := fmt.Sprintf(`import '../../domain/repositories/%s_repository.dart';
+	changes.mkdirAll(domainDir)
+	changes.mkdirAll(dataDir)
 
-class %sRepositoryImpl implements %sRepository {
-  // TODO: implement methods
-}
-`, repoName, pascalCase(repoName), pascalCase(repoName))
+	domainFile := filepath.Join(domainDir, repoName+cfg.Suffixes.Repository+".dart")
+	dataFile := filepath.Join(dataDir, repoName+cfg.Suffixes.RepositoryImpl+".dart")
 
-	writeFile(domainFile, domainContent)
-	writeFile(dataFile, dataContent)
+	writeTemplate(domainFile, "repository.dart.tmpl", templateData{Feature: feature, Name: repoName})
+	writeTemplate(dataFile, "repository_impl.dart.tmpl", templateData{
+		Feature:          feature,
+		Name:             repoName,
+		RepositoryImport: dartRelImport(dataFile, domainFile),
+		Imports:          []string{dartRelImport(dataFile, domainFile)},
+	})
 	writeTest(feature, filepath.Join("data", "repositories", repoName+"_repository_test.dart"), testStub("repository "+repoName))
+	registerRepository(feature, repoName, domainFile, dataFile, "")
 }
 
 func createDatasource(feature, dsName string) {
 	dir := filepath.Join("lib", "features", feature, "data", "datasources")
-	_ = os.MkdirAll(dir, os.ModePerm)
-
-	file := filepath.Join(dir, dsName+"_datasource.dart")
-	content := fmt.Sprintf(`abstract class %sDataSource {
-  // TODO: define data source methods
-}
-
-class %sDataSourceImpl implements %sDataSource {
-  // TODO: implement data source
-}
-`, pascalCase(dsName), pascalCase(dsName), pascalCase(dsName))
+	changes.mkdirAll(dir)
 
-	writeFile(file, content)
+	file := filepath.Join(dir, dsName+cfg.Suffixes.Datasource+".dart")
+	writeTemplate(file, "datasource.dart.tmpl", templateData{Feature: feature, Name: dsName})
 	writeTest(feature, filepath.Join("data", "datasources", dsName+"_datasource_test.dart"), testStub("datasource "+dsName))
+	registerDatasource(feature, dsName, file, false)
 }
 
-func createProvider(feature, providerName string) {
-	dir := filepath.Join("lib", "features", feature, "presentation", "providers")
-	_ = os.MkdirAll(dir, os.ModePerm)
-
-	file := filepath.Join(dir, providerName+"_provider.dart")
-	// provider variable uses lower-case providerName + "Provider"
-	content := fmt.Sprintf(`import 'package:flutter_riverpod/flutter_riverpod.dart';
-import 'package:riverpod_annotation/riverpod_annotation.dart';
-part '%s_provider.g.dart';
-
-@riverpod
-int %s(Ref ref) => 0;
-`,
-		providerName,            // %s -> generated part file name
-		camelCase(providerName), // %s -> provider variable name
-	)
-
-	writeFile(file, content)
-	writeTest(feature, filepath.Join("presentation", "providers", providerName+"_provider_test.dart"), testStub("provider "+providerName))
-}
+func createPage(feature, pageName string, params []routeParam, parent string) {
+	path := pageRoutePath(feature, pageName, params)
+	if parent != "" {
+		parentPath, ok := parentRoutePath(feature, parent)
+		if !ok {
+			return
+		}
+		path = strings.TrimSuffix(parentPath, "/") + routePath(pageName, params)
+	}
+	if !checkRouteCollision(feature, pageName, path) {
+		return
+	}
 
-func createPage(feature, pageName string) {
 	dir := filepath.Join("lib", "features", feature, "presentation", "pages")
-	_ = os.MkdirAll(dir, os.ModePerm)
+	changes.mkdirAll(dir)
+
+	file := filepath.Join(dir, pageName+cfg.Suffixes.Page+".dart")
+	if cfg.StateManagement == stateBloc {
+		writeBlocPage(feature, pageName, file, pageFields(params))
+	} else {
+		writeProviderPage(feature, pageName, file, pageFields(params))
+	}
+	writeTest(feature, filepath.Join("presentation", "pages", pageName+"_page_test.dart"), testStub("page "+pageName))
 
-	file := filepath.Join(dir, pageName+"_page.dart")
+	addPageConstant(feature, pageName, path)
+	// Add route to router.dart
+	appendRoute(feature, pageName, params, parent)
+}
 
+// writeProviderPage writes a page that watches a Riverpod provider, with a UI
+// that fits the provider's kind.
+func writeProviderPage(feature, pageName, file string, fields []dartField) {
 	// Decide provider to import:
 	// prefer a provider named after the page if it exists, else fall back to feature provider
 	selectedProvider := pageName
-	providerPath := filepath.Join("lib", "features", feature, "presentation", "providers", selectedProvider+"_provider.dart")
-	if _, err := os.Stat(providerPath); os.IsNotExist(err) {
+	providerPath := filepath.Join("lib", "features", feature, "presentation", "providers", selectedProvider+cfg.Suffixes.Provider+".dart")
+	if !changes.exists(providerPath) {
 		// fallback to feature-named provider
 		selectedProvider = feature
-		providerPath = filepath.Join("lib", "features", feature, "presentation", "providers", selectedProvider+"_provider.dart")
-		if _, fallbackErr := os.Stat(providerPath); os.IsNotExist(fallbackErr) {
-			createProvider(feature, selectedProvider)
+		providerPath = filepath.Join("lib", "features", feature, "presentation", "providers", selectedProvider+cfg.Suffixes.Provider+".dart")
+		if !changes.exists(providerPath) {
+			createProvider(feature, selectedProvider, providerFunctional, "")
 		}
 	}
-	selectedProviderVar := camelCase(selectedProvider)
-
-	content := fmt.Sprintf(`import 'package:flutter/material.dart';
-import 'package:flutter_riverpod/flutter_riverpod.dart';
-import '../providers/%s_provider.dart';
-
-class %sPage extends ConsumerWidget {
-  const %sPage({super.key});
-
-  @override
-  Widget build(BuildContext context, WidgetRef ref) {
-    final state = ref.watch(%sProvider);
-    return Scaffold(
-      appBar: AppBar(title: Text('%s')),
-      body: Center(),
-    );
-  }
+	kind := providerFunctional
+	if content, err := changes.readFile(providerPath); err == nil {
+		kind = providerKindOf(string(content))
+	}
+	writeTemplate(file, "page.dart.tmpl", templateData{
+		Feature:        feature,
+		Name:           pageName,
+		Kind:           kind,
+		Provider:       selectedProvider,
+		ProviderVar:    providerVar(selectedProvider, kind),
+		ProviderImport: dartRelImport(file, providerPath),
+		Fields:         fields,
+	})
 }
-`, selectedProvider, pascalCase(pageName), pascalCase(pageName), selectedProviderVar, pascalCase(pageName))
 
-	writeFile(file, content)
-	writeTest(feature, filepath.Join("presentation", "pages", pageName+"_page_test.dart"), testStub("page "+pageName))
-
-	addPageConstant(pageName)
-	// Add route to router.dart
-	appendRoute(feature, pageName)
+// pageFilePath returns the location of a page's Dart file.
+func pageFilePath(feature, pageName string) string {
+	return filepath.Join("lib", "features", feature, "presentation", "pages", pageName+cfg.Suffixes.Page+".dart")
 }
 
 func writeFile(path, content string) {
-	if _, err := os.Stat(path); os.IsNotExist(err) {
-		if err := os.WriteFile(path, []byte(content), 0644); err == nil {
-			fmt.Printf("📝 Created %s\n", path)
+	if !changes.exists(path) {
+		if err := changes.writeFile(path, []byte(content)); err == nil {
+			changes.markGenerated(path)
+			changes.createdf("📝 Created %s\n", path)
 		} else {
-			fmt.Printf("❌ Failed writing %s: %v\n", path, err)
+			failf("Failed writing %s: %v", path, err)
 		}
 	} else {
+		changes.skip(path)
 		fmt.Printf("⚠️  File %s already exists (skipped)\n", path)
 	}
 }
 
-func testStub(subject string) string {
-	return fmt.Sprintf(`import 'package:flutter_test/flutter_test.dart';
-
-void main() {
-  test('%s scaffold placeholder', () {
-    expect(true, isTrue);
-  });
+// writeTemplate renders the named template and writes it like writeFile.
+func writeTemplate(path, name string, data templateData) {
+	content, err := renderTemplate(name, data)
+	if err != nil {
+		failf("Failed rendering %s: %v", path, err)
+		return
+	}
+	writeFile(path, content)
 }
-`, subject)
+
+func testStub(subject string) string {
+	content, err := renderTemplate("test.dart.tmpl", templateData{Subject: subject})
+	if err != nil {
+		failf("Failed rendering test stub: %v", err)
+		return ""
+	}
+	return content
 }
 
 func writeTest(feature, filename, content string) {
+	if content == "" {
+		return
+	}
 	path := filepath.Join("test", "features", feature, filename)
-	_ = os.MkdirAll(filepath.Dir(path), os.ModePerm)
+	changes.mkdirAll(filepath.Dir(path))
 
-	if _, err := os.Stat(path); os.IsNotExist(err) {
-		if err := os.WriteFile(path, []byte(content), 0644); err == nil {
-			fmt.Printf("🧪 Created test %s\n", path)
+	if !changes.exists(path) {
+		if err := changes.writeFile(path, []byte(content)); err == nil {
+			changes.markGenerated(path)
+			changes.createdf("🧪 Created test %s\n", path)
 		} else {
-			fmt.Printf("❌ Failed writing test %s: %v\n", path, err)
+			failf("Failed writing test %s: %v", path, err)
 		}
+	} else {
+		changes.skip(path)
 	}
 }
 
@@ -703,8 +638,12 @@ func classifyLegacyTest(name string) string {
 		return filepath.Join("data", "repositories")
 	case strings.HasSuffix(name, "_datasource_test.dart"):
 		return filepath.Join("data", "datasources")
+	case strings.HasSuffix(name, "_model_test.dart"):
+		return filepath.Join("data", "models")
 	case strings.HasSuffix(name, "_provider_test.dart"):
 		return filepath.Join("presentation", "providers")
+	case strings.HasSuffix(name, "_cubit_test.dart"), strings.HasSuffix(name, "_bloc_test.dart"):
+		return filepath.Join("presentation", "bloc")
 	case strings.HasSuffix(name, "_page_test.dart"):
 		return filepath.Join("presentation", "pages")
 	case strings.HasSuffix(name, "_widget_test.dart"):
@@ -718,7 +657,7 @@ func migrateLegacyTests() {
 	root := filepath.Join("test", "features")
 	features, err := os.ReadDir(root)
 	if err != nil {
-		fmt.Printf("❌ Failed to read %s: %v\n", root, err)
+		failf("Failed to read %s: %v", root, err)
 		return
 	}
 
@@ -732,7 +671,7 @@ func migrateLegacyTests() {
 		featureDir := filepath.Join(root, feature.Name())
 		entries, err := os.ReadDir(featureDir)
 		if err != nil {
-			fmt.Printf("❌ Failed to read %s: %v\n", featureDir, err)
+			failf("Failed to read %s: %v", featureDir, err)
 			continue
 		}
 
@@ -750,15 +689,15 @@ func migrateLegacyTests() {
 
 			src := filepath.Join(featureDir, name)
 			dst := filepath.Join(featureDir, targetRelDir, name)
-			if _, err := os.Stat(dst); err == nil {
+			if changes.exists(dst) {
 				fmt.Printf("⚠️  Target exists, skipped: %s\n", dst)
 				skipped++
 				continue
 			}
 
-			_ = os.MkdirAll(filepath.Dir(dst), os.ModePerm)
-			if err := os.Rename(src, dst); err != nil {
-				fmt.Printf("❌ Failed to move %s -> %s: %v\n", src, dst, err)
+			changes.mkdirAll(filepath.Dir(dst))
+			if err := changes.rename(src, dst); err != nil {
+				failf("Failed to move %s -> %s: %v", src, dst, err)
 				skipped++
 				continue
 			}
@@ -770,145 +709,428 @@ func migrateLegacyTests() {
 	fmt.Printf("🎯 Migration complete. moved=%d skipped=%d\n", moved, skipped)
 }
 
-func addPageConstant(pageName string) {
-	constFile := filepath.Join("lib", "core", "page_names.dart")
-	_ = os.MkdirAll(filepath.Dir(constFile), os.ModePerm)
+func addPageConstant(feature, pageName, path string) {
+	constFile := cfg.Paths.PageNames
+	changes.mkdirAll(filepath.Dir(constFile))
 
-	constName := "k" + pascalCase(pageName) + "Page"
-	constLine := fmt.Sprintf("const %s = '/%s';\n", constName, pageName)
+	constName := pageConstName(feature, pageName)
+	constLine := fmt.Sprintf("const %s = '%s';\n", constName, path)
 
 	// Create file with header if missing
-	if _, err := os.Stat(constFile); os.IsNotExist(err) {
+	if !changes.exists(constFile) {
 		header := "// AUTO_GENERATED – do not edit manually.\n\n"
-		if err := os.WriteFile(constFile, []byte(header), 0644); err != nil {
-			fmt.Printf("❌ Failed to create %s: %v\n", constFile, err)
+		if err := changes.writeFile(constFile, []byte(header)); err != nil {
+			failf("Failed to create %s: %v", constFile, err)
 			return
 		}
 	}
 
 	// Read existing
-	data, err := os.ReadFile(constFile)
+	data, err := changes.readFile(constFile)
 	if err != nil {
-		fmt.Printf("❌ Failed to read %s: %v\n", constFile, err)
+		failf("Failed to read %s: %v", constFile, err)
 		return
 	}
-	content := string(data)
-
 	// Only add if it doesn’t already exist
-	if !strings.Contains(content, constName) {
-		if err := os.WriteFile(constFile, append(data, []byte(constLine)...), 0644); err != nil {
-			fmt.Printf("❌ Failed to update %s: %v\n", constFile, err)
+	if _, ok := pageConstValue(constName); !ok {
+		if err := changes.writeFile(constFile, append(data, []byte(constLine)...)); err != nil {
+			failf("Failed to update %s: %v", constFile, err)
 			return
 		}
-		fmt.Printf("🔗 Added constant %s to page_names.dart\n", constName)
+		fmt.Printf("🔗 Added constant %s to %s\n", constName, filepath.Base(constFile))
 	}
 }
 
 func main() {
-	if len(os.Args) < 2 {
+	if code := run(os.Args[1:]); code != 0 {
+		os.Exit(code)
+	}
+}
+
+// run executes one command line and returns the process exit code. Generated
+// changes are only committed when every step succeeded.
+func run(argv []string) int {
+	args, flags, err := parseArgs(argv)
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		return 1
+	}
+	dryRun := flags.has("dry-run")
+	if len(args) < 1 {
 		fmt.Println(`Usage:
-  new feature <name>
-  new page <feature> <pageName>
-  new provider <feature> <providerName>
-  new entity <feature> <entityName>
-  new usecase <feature> <usecaseName>
+  new feature <name> [--openapi <spec.yaml> [--tag <tag>]] [--shell [stateful]]
+  new page <feature> <pageName> [--path-param id:int ...] [--query-param tab:String ...] [--parent <pageName>]
+  new provider <feature> <providerName> [--kind functional|notifier|async_notifier|future|stream] [--usecase <usecaseName>]
+  new provider <feature> <name> [--kind cubit|bloc]   (with state_management: bloc)
+  new cubit <feature> <name>
+  new bloc <feature> <name>
+  new widget <feature> <widgetName> [--stateful|--consumer] [--export]
+  new entity <feature> <entityName> [field:Type ...]
+  new usecase <feature> <usecaseName> [--repo <repoName> [--params "id:int,..."] [--returns Type]]
   new repository <feature> <repoName>
   new datasource <feature> <dsName>
+  new model <feature> <modelName> [field:Type ...]
+  new model <feature> <modelName> --from-json <sample.json>
+  remove feature <name> [--force]
+  remove page <feature> <pageName> [--force]
+  rename feature <oldName> <newName>
+  rename page <feature> <oldPageName> <newPageName>
+  sync routes [--check]
   migrate tests
-  release apk`)
-		return
+  lint [--format text|json|sarif]
+  graph [--format dot|mermaid|json] [--level full|feature|layer] [--feature <name>]
+  list|inspect [features|pages|routes|providers|usecases] [--json]
+  templates eject [name...]
+  release apk
+
+Options:
+  --dry-run  print the planned changes and diffs without writing anything
+  --state    riverpod|bloc, overrides the state_management config key`)
+		return 0
+	}
+	if err := checkArgCount(args); err != nil {
+		fmt.Printf("❌ %v\n", err)
+		return 1
+	}
+
+	loaded, configFile, err := loadConfig(".")
+	if err != nil {
+		fmt.Printf("❌ Invalid config %v\n", err)
+		return 1
+	}
+	cfg = loaded
+	if flags.has("state") {
+		cfg.StateManagement = flags.value("state")
+		if err := cfg.validate(); err != nil {
+			fmt.Printf("❌ Invalid --state: %v\n", err)
+			return 1
+		}
+	}
+	// lint, graph, list and sync --check only read the project; the output of
+	// the first three may be JSON
+	switch args[0] {
+	case "lint":
+		return runLint(flags.value("format"))
+	case "graph":
+		return runGraph(flags.value("format"), flags.value("level"), strings.ToLower(flags.value("feature")))
+	case "list", "inspect":
+		what := ""
+		if len(args) > 1 {
+			what = strings.ToLower(args[1])
+		}
+		return runList(what, flags.has("json"))
+	case "sync":
+		// sync --check only reports drift; it fails without a rollback
+		if flags.has("check") && len(args) > 1 && args[1] == "routes" {
+			return checkRoutes()
+		}
+	}
+	if configFile != "" {
+		fmt.Printf("⚙️  Using config %s\n", configFile)
 	}
 
-	cmd := os.Args[1]
+	if dryRun && args[0] == "release" {
+		fmt.Println("❌ --dry-run is not supported for 'release'")
+		return 1
+	}
+
+	changes = newChangeSet()
+	changes.dryRun = dryRun
+	dispatch(args, flags)
+	if !changes.failed() {
+		if err := changes.stageManifest(); err != nil {
+			failf("Failed to update %s: %v", manifestFile, err)
+		}
+	}
+
+	if dryRun {
+		changes.printPlan()
+		if changes.failed() {
+			return 1
+		}
+		return 0
+	}
+	if changes.failed() {
+		fmt.Printf("❌ %d step(s) failed; nothing was written.\n", len(changes.errs))
+		changes.printRollback()
+		return 1
+	}
+	if err := changes.commit(); err != nil {
+		fmt.Printf("❌ Failed to apply changes: %v\n", err)
+		changes.printRollback()
+		return 1
+	}
+	return 0
+}
+
+// dispatch runs the command named by args, staging its file changes.
+func dispatch(args []string, flags cliFlags) {
+	cmd := args[0]
 	switch cmd {
 	case "new":
-		if len(os.Args) < 4 {
-			fmt.Println("❌ missing arguments for 'new'")
+		if len(args) < 3 {
+			failf("missing arguments for 'new'")
 			return
 		}
-		subCmd := os.Args[2]
+		subCmd := args[1]
 		switch subCmd {
 		case "feature":
-			name := strings.ToLower(os.Args[3])
+			name := strings.ToLower(args[2])
+			if flags.has("tag") && flags.value("openapi") == "" {
+				failf("--tag requires --openapi <spec>")
+				return
+			}
+			if flags.has("shell") {
+				kind := flags.value("shell")
+				switch kind {
+				case "", shellPlain:
+					kind = shellPlain
+				case shellStateful:
+				default:
+					failf("unknown --shell value %q (use --shell or --shell stateful)", kind)
+					return
+				}
+				createShell(name, kind)
+			}
+			if spec := flags.value("openapi"); spec != "" {
+				createFeatureFromOpenAPI(name, spec, flags.value("tag"))
+				return
+			}
 			createFeature(name)
 		case "page":
-			if len(os.Args) < 5 {
-				fmt.Println("❌ new page requires <feature> <pageName>")
+			if len(args) < 4 {
+				failf("new page requires <feature> <pageName>")
 				return
 			}
-			feature := strings.ToLower(os.Args[3])
-			page := strings.ToLower(os.Args[4])
-			createPage(feature, page)
+			feature := strings.ToLower(args[2])
+			page := strings.ToLower(args[3])
+			params, err := parseRouteParams(splitSpecList(flags["path-param"]), splitSpecList(flags["query-param"]))
+			if err != nil {
+				failf("%v", err)
+				return
+			}
+			createPage(feature, page, params, strings.ToLower(flags.value("parent")))
 		case "provider":
-			if len(os.Args) < 5 {
-				fmt.Println("❌ new provider requires <feature> <providerName>")
+			if len(args) < 4 {
+				failf("new provider requires <feature> <providerName>")
+				return
+			}
+			feature := strings.ToLower(args[2])
+			provider := strings.ToLower(args[3])
+			if cfg.StateManagement == stateBloc {
+				kind, err := parseBlocKind(strings.ToLower(flags.value("kind")))
+				if err != nil {
+					failf("%v", err)
+					return
+				}
+				createBloc(feature, provider, kind)
+				return
+			}
+			kind, err := parseProviderKind(flags.value("kind"))
+			if err != nil {
+				failf("%v", err)
+				return
+			}
+			usecase := strings.ToLower(flags.value("usecase"))
+			if usecase != "" && kind != providerAsyncNotifier {
+				failf("--usecase requires --kind async_notifier")
 				return
 			}
-			feature := strings.ToLower(os.Args[3])
-			provider := strings.ToLower(os.Args[4])
-			createProvider(feature, provider)
+			createProvider(feature, provider, kind, usecase)
+		case "cubit", "bloc":
+			if len(args) < 4 {
+				failf("new %s requires <feature> <name>", subCmd)
+				return
+			}
+			createBloc(strings.ToLower(args[2]), strings.ToLower(args[3]), subCmd)
+		case "widget":
+			if len(args) < 4 {
+				failf("new widget requires <feature> <widgetName>")
+				return
+			}
+			feature := strings.ToLower(args[2])
+			widget := strings.ToLower(args[3])
+			kind := widgetStateless
+			switch {
+			case flags.has("stateful") && flags.has("consumer"):
+				failf("--stateful and --consumer cannot be combined")
+				return
+			case flags.has("stateful"):
+				kind = widgetStateful
+			case flags.has("consumer"):
+				kind = widgetConsumer
+			}
+			createWidget(feature, widget, kind, flags.has("export"))
 		case "entity":
-			if len(os.Args) < 5 {
-				fmt.Println("❌ new entity requires <feature> <entityName>")
+			if len(args) < 4 {
+				failf("new entity requires <feature> <entityName>")
 				return
 			}
-			feature := strings.ToLower(os.Args[3])
-			entity := strings.ToLower(os.Args[4])
-			createEntity(feature, entity)
+			feature := strings.ToLower(args[2])
+			entity := strings.ToLower(args[3])
+			fields, err := parseFieldSpecs(args[4:])
+			if err != nil {
+				failf("%v", err)
+				return
+			}
+			createEntity(feature, entity, fields)
 		case "usecase":
-			if len(os.Args) < 5 {
-				fmt.Println("❌ new usecase requires <feature> <usecaseName>")
+			if len(args) < 4 {
+				failf("new usecase requires <feature> <usecaseName>")
+				return
+			}
+			feature := strings.ToLower(args[2])
+			usecase := strings.ToLower(args[3])
+			repo := strings.ToLower(flags.value("repo"))
+			if repo == "" {
+				if flags.has("params") || flags.has("returns") {
+					failf("--params and --returns require --repo <repoName>")
+					return
+				}
+				createUsecase(feature, usecase)
+				return
+			}
+			params, err := parseFieldSpecs(splitSpecList(flags["params"]))
+			if err != nil {
+				failf("%v", err)
 				return
 			}
-			feature := strings.ToLower(os.Args[3])
-			usecase := strings.ToLower(os.Args[4])
-			createUsecase(feature, usecase)
+			returns := "void"
+			if flags.value("returns") != "" {
+				if returns, err = normalizeDartType(flags.value("returns")); err != nil {
+					failf("invalid --returns: %v", err)
+					return
+				}
+			}
+			createRepositoryUsecase(feature, usecase, repo, params, returns)
 		case "repository":
-			if len(os.Args) < 5 {
-				fmt.Println("❌ new repository requires <feature> <repoName>")
+			if len(args) < 4 {
+				failf("new repository requires <feature> <repoName>")
 				return
 			}
-			feature := strings.ToLower(os.Args[3])
-			repo := strings.ToLower(os.Args[4])
+			feature := strings.ToLower(args[2])
+			repo := strings.ToLower(args[3])
 			createRepository(feature, repo)
 		case "datasource":
-			if len(os.Args) < 5 {
-				fmt.Println("❌ new datasource requires <feature> <dsName>")
+			if len(args) < 4 {
+				failf("new datasource requires <feature> <dsName>")
 				return
 			}
-			feature := strings.ToLower(os.Args[3])
-			ds := strings.ToLower(os.Args[4])
+			feature := strings.ToLower(args[2])
+			ds := strings.ToLower(args[3])
 			createDatasource(feature, ds)
+		case "model":
+			if len(args) < 4 {
+				failf("new model requires <feature> <modelName>")
+				return
+			}
+			feature := strings.ToLower(args[2])
+			model := strings.ToLower(args[3])
+			if sample := flags.value("from-json"); sample != "" {
+				if len(args) > 4 {
+					failf("--from-json cannot be combined with field specs")
+					return
+				}
+				createModelFromJSON(feature, model, sample)
+				return
+			}
+			fields, err := parseFieldSpecs(args[4:])
+			if err != nil {
+				failf("%v", err)
+				return
+			}
+			createModel(feature, model, fields)
 		default:
-			fmt.Println("❌ Unknown subcommand. Use: feature | page | provider | entity | usecase | repository | datasource")
+			failf("Unknown subcommand. Use: feature | page | provider | entity | usecase | repository | datasource | model")
+		}
+	case "remove":
+		if len(args) < 3 {
+			failf("missing arguments for 'remove'")
+			return
+		}
+		force := flags.has("force")
+		subCmd := args[1]
+		switch subCmd {
+		case "feature":
+			removeFeature(strings.ToLower(args[2]), force)
+		case "page":
+			if len(args) < 4 {
+				failf("remove page requires <feature> <pageName>")
+				return
+			}
+			removePage(strings.ToLower(args[2]), strings.ToLower(args[3]), force)
+		default:
+			failf("Unknown remove subcommand. Use: feature | page")
+		}
+	case "rename":
+		if len(args) < 4 {
+			failf("missing arguments for 'rename'")
+			return
+		}
+		subCmd := args[1]
+		switch subCmd {
+		case "feature":
+			renameFeature(strings.ToLower(args[2]), strings.ToLower(args[3]))
+		case "page":
+			if len(args) < 5 {
+				failf("rename page requires <feature> <oldPageName> <newPageName>")
+				return
+			}
+			renamePage(strings.ToLower(args[2]), strings.ToLower(args[3]), strings.ToLower(args[4]))
+		default:
+			failf("Unknown rename subcommand. Use: feature | page")
+		}
+	case "sync":
+		if len(args) < 2 {
+			failf("missing arguments for 'sync'")
+			return
+		}
+		subCmd := args[1]
+		switch subCmd {
+		case "routes":
+			syncRoutes()
+		default:
+			failf("Unknown sync subcommand. Use: routes")
 		}
 	case "migrate":
-		if len(os.Args) < 3 {
-			fmt.Println("❌ missing arguments for 'migrate'")
+		if len(args) < 2 {
+			failf("missing arguments for 'migrate'")
 			return
 		}
-		subCmd := os.Args[2]
+		subCmd := args[1]
 		switch subCmd {
 		case "tests":
 			migrateLegacyTests()
 		default:
-			fmt.Println("❌ Unknown migrate subcommand. Use: tests")
+			failf("Unknown migrate subcommand. Use: tests")
+		}
+	case "templates":
+		if len(args) < 2 {
+			failf("missing arguments for 'templates'")
+			return
+		}
+		subCmd := args[1]
+		switch subCmd {
+		case "eject":
+			ejectTemplates(args[2:])
+		default:
+			failf("Unknown templates subcommand. Use: eject")
 		}
 	case "release":
-		if len(os.Args) < 3 {
-			fmt.Println("❌ missing arguments for 'release'")
+		if len(args) < 2 {
+			failf("missing arguments for 'release'")
 			return
 		}
-		subCmd := os.Args[2]
+		subCmd := args[1]
 		switch subCmd {
 		case "capk":
 			releaseAPK(true)
 		case "apk":
 			releaseAPK(false)
 		default:
-			fmt.Println("❌ Unknown release subcommand. Use: upload-apk")
+			failf("Unknown release subcommand. Use: upload-apk")
 		}
 	default:
-		fmt.Println("❌ Unknown command. Use: new")
+		failf("Unknown command. Use: new")
 	}
 }
@@ -9,6 +9,8 @@ import (
 	"testing"
 )
 
+// withTempDir runs the test in an empty project whose pubspec.yaml names the
+// package shop.
 func withTempDir(t *testing.T) string {
 	t.Helper()
 	wd, err := os.Getwd()
@@ -23,10 +25,19 @@ func withTempDir(t *testing.T) string {
 	if err := os.Chdir(tmp); err != nil {
 		t.Fatalf("chdir temp failed: %v", err)
 	}
+	if err := os.WriteFile("pubspec.yaml", []byte("name: shop\n"), 0644); err != nil {
+		t.Fatalf("write pubspec failed: %v", err)
+	}
 	return tmp
 }
 
 func runMain(t *testing.T, args ...string) string {
+	t.Helper()
+	out, _ := runMainCode(t, args...)
+	return out
+}
+
+func runMainCode(t *testing.T, args ...string) (string, int) {
 	t.Helper()
 	oldArgs := os.Args
 	oldStdout := os.Stdout
@@ -46,7 +57,7 @@ func runMain(t *testing.T, args ...string) string {
 		done <- b.String()
 	}()
 
-	main()
+	code := run(os.Args[1:])
 
 	_ = w.Close()
 	out := <-done
@@ -54,7 +65,7 @@ func runMain(t *testing.T, args ...string) string {
 
 	os.Stdout = oldStdout
 	os.Args = oldArgs
-	return out
+	return out, code
 }
 
 func mustReadFile(t *testing.T, path string) string {
@@ -73,6 +84,13 @@ func mustExist(t *testing.T, path string) {
 	}
 }
 
+func mustNotExist(t *testing.T, path string) {
+	t.Helper()
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("expected path to be absent: %s (%v)", path, err)
+	}
+}
+
 func TestCommandUsageAndValidation(t *testing.T) {
 	withTempDir(t)
 
@@ -89,9 +107,24 @@ func TestCommandUsageAndValidation(t *testing.T) {
 		{name: "missing usecase args", args: []string{"new", "usecase", "orders"}, expect: "new usecase requires <feature> <usecaseName>"},
 		{name: "missing repository args", args: []string{"new", "repository", "orders"}, expect: "new repository requires <feature> <repoName>"},
 		{name: "missing datasource args", args: []string{"new", "datasource", "orders"}, expect: "new datasource requires <feature> <dsName>"},
+		{name: "missing model args", args: []string{"new", "model", "orders"}, expect: "new model requires <feature> <modelName>"},
+		{name: "tag without openapi", args: []string{"new", "feature", "orders", "--tag", "orders"}, expect: "--tag requires --openapi <spec>"},
+		{name: "unknown provider kind", args: []string{"new", "provider", "orders", "cart", "--kind", "bloc"}, expect: "unknown provider kind"},
+		{name: "provider usecase without async kind", args: []string{"new", "provider", "orders", "cart", "--usecase", "get"}, expect: "--usecase requires --kind async_notifier"},
+		{name: "missing widget args", args: []string{"new", "widget", "orders"}, expect: "new widget requires <feature> <widgetName>"},
+		{name: "widget stateful and consumer", args: []string{"new", "widget", "orders", "card", "--stateful", "--consumer"}, expect: "--stateful and --consumer cannot be combined"},
+		{name: "usecase params without repo", args: []string{"new", "usecase", "orders", "get", "--params", "id:int"}, expect: "--params and --returns require --repo <repoName>"},
 		{name: "unknown new subcommand", args: []string{"new", "unknown", "x"}, expect: "Unknown subcommand"},
+		{name: "missing remove args", args: []string{"remove", "page"}, expect: "missing arguments for 'remove'"},
+		{name: "missing remove page args", args: []string{"remove", "page", "orders"}, expect: "remove page requires <feature> <pageName>"},
+		{name: "unknown remove subcommand", args: []string{"remove", "unknown", "x"}, expect: "Unknown remove subcommand"},
+		{name: "missing rename args", args: []string{"rename", "feature", "orders"}, expect: "missing arguments for 'rename'"},
+		{name: "missing rename page args", args: []string{"rename", "page", "orders", "details"}, expect: "rename page requires <feature> <oldPageName> <newPageName>"},
+		{name: "unknown rename subcommand", args: []string{"rename", "unknown", "x", "y"}, expect: "Unknown rename subcommand"},
 		{name: "missing migrate args", args: []string{"migrate"}, expect: "missing arguments for 'migrate'"},
 		{name: "unknown migrate subcommand", args: []string{"migrate", "unknown"}, expect: "Unknown migrate subcommand"},
+		{name: "missing templates args", args: []string{"templates"}, expect: "missing arguments for 'templates'"},
+		{name: "unknown templates subcommand", args: []string{"templates", "unknown"}, expect: "Unknown templates subcommand"},
 		{name: "missing release args", args: []string{"release"}, expect: "missing arguments for 'release'"},
 		{name: "unknown release subcommand", args: []string{"release", "unknown"}, expect: "Unknown release subcommand"},
 		{name: "unknown root command", args: []string{"oops", "x"}, expect: "Unknown command"},
@@ -99,14 +132,46 @@ func TestCommandUsageAndValidation(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			out := runMain(t, tt.args...)
+			out, code := runMainCode(t, tt.args...)
 			if !strings.Contains(out, tt.expect) {
 				t.Fatalf("expected output to contain %q, got:\n%s", tt.expect, out)
 			}
+			want := 1
+			if tt.args == nil {
+				want = 0
+			}
+			if code != want {
+				t.Fatalf("expected exit %d, got %d:\n%s", want, code, out)
+			}
 		})
 	}
 }
 
+func TestUnknownOptionsAndExtraArgumentsFail(t *testing.T) {
+	withTempDir(t)
+
+	for _, tt := range []struct {
+		args   []string
+		expect string
+	}{
+		{[]string{"new", "feature", "orders", "--dryrun"}, "unknown option --dryrun"},
+		{[]string{"new", "page", "orders", "detail", "--path", "id:int"}, "unknown option --path"},
+		{[]string{"new", "page", "orders", "detail", "extra"}, `unexpected argument "extra" for 'new page'`},
+		{[]string{"remove", "feature", "orders", "cart"}, `unexpected argument "cart" for 'remove feature'`},
+		{[]string{"lint", "lib"}, `unexpected argument "lib" for 'lint'`},
+	} {
+		out, code := runMainCode(t, tt.args...)
+		if code != 1 || !strings.Contains(out, "❌ "+tt.expect) {
+			t.Fatalf("%v: expected exit 1 with %q, got code %d:\n%s", tt.args, tt.expect, code, out)
+		}
+	}
+	mustNotExist(t, "lib")
+
+	if out, code := runMainCode(t, "new", "entity", "orders", "order", "id:int", "name:String", "--dry-run"); code != 0 {
+		t.Fatalf("expected field specs to be accepted, got code %d:\n%s", code, out)
+	}
+}
+
 func TestNewFeatureCreatesScaffold(t *testing.T) {
 	withTempDir(t)
 	_ = runMain(t, "new", "feature", "orders")
@@ -220,7 +285,9 @@ func TestMigrateTestsMovesLegacyFlatFiles(t *testing.T) {
 		"orders_repository_test.dart": "data/repositories",
 		"remote_datasource_test.dart": "data/datasources",
 		"orders_provider_test.dart":   "presentation/providers",
+		"cart_cubit_test.dart":        "presentation/bloc",
 		"details_page_test.dart":      "presentation/pages",
+		"order_model_test.dart":       "data/models",
 		"misc_test.dart":              "",
 	}
 
@@ -0,0 +1,114 @@
+// manifest.go
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// manifestFile records a content hash for every file farch generated, so later
+// commands can tell whether a file was edited by hand since.
+var manifestFile = filepath.Join(".farch", "manifest.json")
+
+type manifest struct {
+	Files map[string]string `json:"files"`
+}
+
+func contentHash(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// manifestKey normalizes a path for use as a manifest key.
+func manifestKey(path string) string {
+	return filepath.ToSlash(filepath.Clean(path))
+}
+
+// loadManifest reads the manifest through the change set. A missing manifest
+// is empty.
+func loadManifest() (manifest, error) {
+	m := manifest{Files: map[string]string{}}
+	if !changes.exists(manifestFile) {
+		return m, nil
+	}
+	data, err := changes.readFile(manifestFile)
+	if err != nil {
+		return m, err
+	}
+	if err := json.Unmarshal(data, &m); err != nil {
+		return m, fmt.Errorf("%s: %w", manifestFile, err)
+	}
+	if m.Files == nil {
+		m.Files = map[string]string{}
+	}
+	return m, nil
+}
+
+// isGeneratedOutput reports whether path is a build_runner output, which is
+// regenerated rather than hand-edited and never tracked.
+func isGeneratedOutput(path string) bool {
+	return strings.HasSuffix(path, ".g.dart") || strings.HasSuffix(path, ".freezed.dart")
+}
+
+// pristine reports whether the file at path still has the content farch
+// generated for it. Untracked files are never pristine.
+func (m manifest) pristine(path string) bool {
+	if isGeneratedOutput(path) {
+		return true
+	}
+	want, ok := m.Files[manifestKey(path)]
+	if !ok {
+		return false
+	}
+	data, err := changes.readFile(path)
+	if err != nil {
+		return false
+	}
+	return contentHash(data) == want
+}
+
+// stageManifest folds the change set's generated, moved and deleted files into
+// the manifest and stages the updated manifest.
+func (c *changeSet) stageManifest() error {
+	if len(c.generated) == 0 && len(c.moved) == 0 && !c.hasDeletes() {
+		return nil
+	}
+	m, err := loadManifest()
+	if err != nil {
+		return err
+	}
+
+	for dst, src := range c.moved {
+		if hash, ok := m.Files[manifestKey(src)]; ok {
+			m.Files[manifestKey(dst)] = hash
+		}
+	}
+	for _, path := range c.order {
+		f := c.files[path]
+		switch {
+		case f.deleted:
+			delete(m.Files, manifestKey(path))
+		case c.generated[path]:
+			m.Files[manifestKey(path)] = contentHash(f.after)
+		}
+	}
+
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	return c.writeFile(manifestFile, append(data, '\n'))
+}
+
+func (c *changeSet) hasDeletes() bool {
+	for _, path := range c.order {
+		if c.files[path].deleted {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,385 @@
+// model.go
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// jsonScalars are the Dart types that map directly to a JSON value.
+var jsonScalars = map[string]bool{
+	"int": true, "double": true, "num": true, "String": true, "bool": true,
+	"DateTime": true, "dynamic": true, "Object": true,
+}
+
+// entityFieldRe matches a "final Type name;" field declaration in an entity.
+var entityFieldRe = regexp.MustCompile(`(?m)^\s+final\s+([A-Za-z_][A-Za-z0-9_<>, ?]*?)\s+([A-Za-z_][A-Za-z0-9_]*)\s*;`)
+
+// createModel writes a data model with fromJson/toJson. When the matching
+// entity exists it adds fromEntity/toEntity and, without field specs, copies
+// the entity's fields.
+func createModel(feature, modelName string, fields []dartField) {
+	dir := filepath.Join("lib", "features", feature, "data", "models")
+	changes.mkdirAll(dir)
+	file := filepath.Join(dir, modelName+cfg.Suffixes.Model+".dart")
+
+	entityFile := filepath.Join("lib", "features", feature, "domain", "entities", modelName+cfg.Suffixes.Entity+".dart")
+	hasEntity := changes.exists(entityFile)
+	if len(fields) == 0 && hasEntity {
+		data, err := changes.readFile(entityFile)
+		if err != nil {
+			failf("Failed to read %s: %v", entityFile, err)
+			return
+		}
+		fields = entityFields(string(data))
+	}
+	if len(fields) == 0 {
+		fields = defaultEntityFields
+	}
+	fields = modelFields(fields)
+
+	data := templateData{
+		Feature: feature,
+		Name:    modelName,
+		Fields:  fields,
+	}
+	if hasEntity {
+		data.EntityImport = dartRelImport(file, entityFile)
+	}
+	data.Imports = classImports(file, fields, func(class string) string {
+		return filepath.Join(dir, snakeCase(strings.TrimSuffix(class, "Model"))+cfg.Suffixes.Model+".dart")
+	})
+	writeTemplate(file, "model.dart.tmpl", data)
+
+	var ok bool
+	if data.ModelImport, ok = testImport(file); !ok {
+		return
+	}
+	data.SampleJSON = sampleJSONMap(fields)
+	content, err := renderTemplate("model_test.dart.tmpl", data)
+	if err != nil {
+		failf("Failed rendering model test: %v", err)
+		return
+	}
+	writeTest(feature, filepath.Join("data", "models", modelName+"_model_test.dart"), content)
+}
+
+// entityFields reads the "final Type name;" fields of an entity class.
+func entityFields(content string) []dartField {
+	var fields []dartField
+	for _, m := range entityFieldRe.FindAllStringSubmatch(content, -1) {
+		typ, err := normalizeDartType(m[1])
+		if err != nil {
+			continue
+		}
+		fields = append(fields, dartField{Name: m[2], Type: typ})
+	}
+	return fields
+}
+
+// modelFields maps entity field types to model types: every custom class T
+// becomes TModel, so nested values get their own JSON handling.
+func modelFields(fields []dartField) []dartField {
+	out := make([]dartField, len(fields))
+	for i, f := range fields {
+		f.Type = mapCustomTypes(f.Type, func(name string) string {
+			if strings.HasSuffix(name, "Model") {
+				return name
+			}
+			return name + "Model"
+		})
+		out[i] = f
+	}
+	return out
+}
+
+// mapCustomTypes rewrites every non-JSON, non-collection type name in typ.
+func mapCustomTypes(typ string, fn func(string) string) string {
+	name, args, nullable := splitDartType(typ)
+	suffix := ""
+	if nullable {
+		suffix = "?"
+	}
+	if len(args) > 0 {
+		mapped := make([]string, len(args))
+		for i, a := range args {
+			mapped[i] = mapCustomTypes(a, fn)
+		}
+		return name + "<" + strings.Join(mapped, ", ") + ">" + suffix
+	}
+	if jsonScalars[name] || name == "List" || name == "Set" || name == "Map" {
+		return typ
+	}
+	return fn(name) + suffix
+}
+
+// pendingClassFiles holds the class files a batch generation is about to
+// write; classImports treats them as present.
+var pendingClassFiles = map[string]bool{}
+
+// classImports returns relative imports from file to the classes its fields
+// refer to, for those whose file (as located by pathOf) exists.
+func classImports(file string, fields []dartField, pathOf func(class string) string) []string {
+	seen := map[string]bool{}
+	var imports []string
+	for _, f := range fields {
+		mapCustomTypes(f.Type, func(class string) string {
+			path := pathOf(class)
+			if path != file && !seen[path] && (pendingClassFiles[path] || changes.exists(path)) {
+				seen[path] = true
+				imports = append(imports, dartRelImport(file, path))
+			}
+			return class
+		})
+	}
+	sort.Strings(imports)
+	return imports
+}
+
+// splitDartType splits "Map<String, int>?" into "Map", ["String", "int"], true.
+func splitDartType(typ string) (string, []string, bool) {
+	f := dartField{Type: typ}
+	name := f.BaseType()
+	if i := strings.Index(name, "<"); i != -1 {
+		name = name[:i]
+	}
+	return name, f.TypeArgs(), f.Nullable()
+}
+
+// JSONKey is the key used for the field on the wire.
+func (f dartField) JSONKey() string {
+	if f.Key != "" {
+		return f.Key
+	}
+	return f.Name
+}
+
+// FromJSON is the expression that reads the field from a decoded JSON map.
+func (f dartField) FromJSON(jsonVar string) string {
+	return fromJSONExpr(f.Type, fmt.Sprintf("%s['%s']", jsonVar, f.JSONKey()), 0)
+}
+
+// ToJSON is the expression that writes the field into a JSON map.
+func (f dartField) ToJSON() string {
+	return toJSONExpr(f.Type, f.Name, 0)
+}
+
+// ToEntity converts the model field into the entity's field value.
+func (f dartField) ToEntity() string {
+	return toEntityExpr(f.Type, f.Name)
+}
+
+// FromEntity converts the entity's field value into the model field.
+func (f dartField) FromEntity(entityVar string) string {
+	return fromEntityExpr(f.Type, entityVar+"."+f.Name)
+}
+
+// toEntityExpr converts expr, a value of model type typ, into its entity.
+func toEntityExpr(typ, expr string) string {
+	return mapModelExpr(typ, expr, 0, func(name, expr string, nullable bool) string {
+		if nullable {
+			return expr + "?.toEntity()"
+		}
+		return expr + ".toEntity()"
+	})
+}
+
+// fromEntityExpr converts expr, an entity value, into model type typ.
+func fromEntityExpr(typ, expr string) string {
+	return mapModelExpr(typ, expr, 0, func(name, expr string, nullable bool) string {
+		if nullable {
+			// a local is promoted by the null check; a field is not
+			value := expr
+			if strings.Contains(expr, ".") {
+				value += "!"
+			}
+			return expr + " == null ? null : " + name + ".fromEntity(" + value + ")"
+		}
+		return name + ".fromEntity(" + expr + ")"
+	})
+}
+
+func lambdaVar(depth int) string {
+	if depth == 0 {
+		return "e"
+	}
+	return fmt.Sprintf("e%d", depth)
+}
+
+func fromJSONExpr(typ, expr string, depth int) string {
+	name, args, nullable := splitDartType(typ)
+	var conv string
+	switch {
+	case name == "int":
+		conv = "(" + expr + " as num).toInt()"
+	case name == "double":
+		conv = "(" + expr + " as num).toDouble()"
+	case name == "num", name == "String", name == "bool", name == "Object":
+		conv = expr + " as " + name
+	case name == "dynamic":
+		return expr
+	case name == "DateTime":
+		conv = "DateTime.parse(" + expr + " as String)"
+	case (name == "List" || name == "Set") && len(args) == 1:
+		v := lambdaVar(depth)
+		conv = "(" + expr + " as List<dynamic>).map((" + v + ") => " + fromJSONExpr(args[0], v, depth+1) + ").to" + name + "()"
+	case name == "Map" && len(args) == 2:
+		k, v := "k", lambdaVar(depth)
+		if depth > 0 {
+			k = fmt.Sprintf("k%d", depth)
+		}
+		conv = "(" + expr + " as Map<String, dynamic>).map((" + k + ", " + v + ") => MapEntry(" + k + ", " + fromJSONExpr(args[1], v, depth+1) + "))"
+	default:
+		conv = name + ".fromJson(" + expr + " as Map<String, dynamic>)"
+	}
+	if nullable {
+		return expr + " == null ? null : " + conv
+	}
+	return conv
+}
+
+// jsonNeedsConversion reports whether values of typ need mapping before they
+// can be JSON encoded.
+func jsonNeedsConversion(typ string) bool {
+	name, args, _ := splitDartType(typ)
+	switch {
+	case name == "DateTime", name == "Set":
+		return true
+	case jsonScalars[name]:
+		return false
+	case name == "List" || name == "Map":
+		for _, a := range args {
+			if jsonNeedsConversion(a) {
+				return true
+			}
+		}
+		return false
+	default:
+		return true
+	}
+}
+
+func toJSONExpr(typ, expr string, depth int) string {
+	name, args, nullable := splitDartType(typ)
+	if !jsonNeedsConversion(typ) {
+		return expr
+	}
+	dot := "."
+	if nullable {
+		dot = "?."
+	}
+	switch {
+	case name == "DateTime":
+		return expr + dot + "toIso8601String()"
+	case (name == "List" || name == "Set") && len(args) == 1:
+		if !jsonNeedsConversion(args[0]) {
+			return expr + dot + "toList()"
+		}
+		v := lambdaVar(depth)
+		return expr + dot + "map((" + v + ") => " + toJSONExpr(args[0], v, depth+1) + ").toList()"
+	case name == "Map" && len(args) == 2:
+		k, v := "k", lambdaVar(depth)
+		if depth > 0 {
+			k = fmt.Sprintf("k%d", depth)
+		}
+		return expr + dot + "map((" + k + ", " + v + ") => MapEntry(" + k + ", " + toJSONExpr(args[1], v, depth+1) + "))"
+	default:
+		return expr + dot + "toJson()"
+	}
+}
+
+// modelTypeNeedsMapping reports whether typ contains a model class.
+func modelTypeNeedsMapping(typ string) bool {
+	name, args, _ := splitDartType(typ)
+	if len(args) > 0 {
+		for _, a := range args {
+			if modelTypeNeedsMapping(a) {
+				return true
+			}
+		}
+		return false
+	}
+	return !jsonScalars[name]
+}
+
+// mapModelExpr converts between model and entity values, applying leaf to
+// every model class value and walking through collections.
+func mapModelExpr(typ, expr string, depth int, leaf func(name, expr string, nullable bool) string) string {
+	if !modelTypeNeedsMapping(typ) {
+		return expr
+	}
+	name, args, nullable := splitDartType(typ)
+	dot := "."
+	if nullable {
+		dot = "?."
+	}
+	switch {
+	case (name == "List" || name == "Set") && len(args) == 1:
+		v := lambdaVar(depth)
+		return expr + dot + "map((" + v + ") => " + mapModelExpr(args[0], v, depth+1, leaf) + ").to" + name + "()"
+	case name == "Map" && len(args) == 2:
+		k, v := "k", lambdaVar(depth)
+		if depth > 0 {
+			k = fmt.Sprintf("k%d", depth)
+		}
+		return expr + dot + "map((" + k + ", " + v + ") => MapEntry(" + k + ", " + mapModelExpr(args[1], v, depth+1, leaf) + "))"
+	default:
+		return leaf(name, expr, nullable)
+	}
+}
+
+// sampleJSONValue returns a Dart literal that fromJson accepts for typ and
+// toJson writes back unchanged, or false when there is none (model classes).
+func sampleJSONValue(typ string) (string, bool) {
+	name, args, _ := splitDartType(typ)
+	switch {
+	case name == "int", name == "num":
+		return "1", true
+	case name == "double":
+		return "1.5", true
+	case name == "String", name == "Object", name == "dynamic":
+		return "'value'", true
+	case name == "bool":
+		return "true", true
+	case name == "DateTime":
+		return "'2024-01-01T00:00:00.000'", true
+	case (name == "List" || name == "Set") && len(args) == 1:
+		v, ok := sampleJSONValue(args[0])
+		return "[" + v + "]", ok
+	case name == "Map" && len(args) == 2:
+		v, ok := sampleJSONValue(args[1])
+		return "{'key': " + v + "}", ok
+	default:
+		return "", false
+	}
+}
+
+// sampleJSONMap returns the entries of a JSON map literal for a round-trip
+// test, or nil when some field has no sample value.
+func sampleJSONMap(fields []dartField) []string {
+	entries := make([]string, 0, len(fields))
+	for _, f := range fields {
+		v, ok := sampleJSONValue(f.Type)
+		if !ok {
+			return nil
+		}
+		entries = append(entries, fmt.Sprintf("'%s': %s", f.JSONKey(), v))
+	}
+	return entries
+}
+
+// testImport returns the package: URI tests import libFile by. A relative
+// import would reach into lib/ and give its libraries a second identity, so it
+// fails when pubspec.yaml does not name the package.
+func testImport(libFile string) (string, bool) {
+	rel := filepath.ToSlash(strings.TrimPrefix(libFile, "lib"+string(filepath.Separator)))
+	pkg := packageName()
+	if pkg == "" {
+		failf("pubspec.yaml has no package name; add \"name: <package>\" so generated tests can import %s as package:<package>/%s", libFile, rel)
+		return "", false
+	}
+	return "package:" + pkg + "/" + rel, true
+}
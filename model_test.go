@@ -0,0 +1,61 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestNewModelMapsMatchingEntity(t *testing.T) {
+	withTempDir(t)
+	_ = runMain(t, "new", "entity", "shop", "order", "id:int", "items:List<LineItem>", "placed_at:DateTime?", "address:Address?")
+	_ = runMain(t, "new", "model", "shop", "order")
+
+	content := mustReadFile(t, filepath.Join("lib", "features", "shop", "data", "models", "order_model.dart"))
+	for _, expect := range []string{
+		"import '../../domain/entities/order.dart';",
+		"  final List<LineItemModel> items;",
+		"      id: (json['id'] as num).toInt(),",
+		"      items: (json['items'] as List<dynamic>).map((e) => LineItemModel.fromJson(e as Map<String, dynamic>)).toList(),",
+		"      placedAt: json['placedAt'] == null ? null : DateTime.parse(json['placedAt'] as String),",
+		"      'placedAt': placedAt?.toIso8601String(),",
+		"  factory OrderModel.fromEntity(Order entity) {",
+		"      address: entity.address == null ? null : AddressModel.fromEntity(entity.address!),",
+		"  Order toEntity() {",
+		"      items: items.map((e) => e.toEntity()).toList(),",
+		"      address: address?.toEntity(),",
+	} {
+		if !strings.Contains(content, expect) {
+			t.Fatalf("expected model to contain %q, got:\n%s", expect, content)
+		}
+	}
+	mustExist(t, filepath.Join("test", "features", "shop", "data", "models", "order_model_test.dart"))
+}
+
+func TestNewModelWithoutEntityWritesRoundTripTest(t *testing.T) {
+	withTempDir(t)
+	if err := os.WriteFile("pubspec.yaml", []byte("name: shop\n"), 0644); err != nil {
+		t.Fatalf("write pubspec failed: %v", err)
+	}
+	_ = runMain(t, "new", "model", "shop", "price", "id:int", "amount:double?", "tags:Map<String,List<int>>")
+
+	content := mustReadFile(t, filepath.Join("lib", "features", "shop", "data", "models", "price_model.dart"))
+	if strings.Contains(content, "toEntity") || strings.Contains(content, "import ") {
+		t.Fatalf("model without entity should not map to one, got:\n%s", content)
+	}
+	if !strings.Contains(content, "(e as List<dynamic>).map((e1) => (e1 as num).toInt()).toList()") {
+		t.Fatalf("nested collection conversion missing, got:\n%s", content)
+	}
+
+	test := mustReadFile(t, filepath.Join("test", "features", "shop", "data", "models", "price_model_test.dart"))
+	for _, expect := range []string{
+		"import 'package:shop/features/shop/data/models/price_model.dart';",
+		"'tags': {'key': [1]},",
+		"expect(PriceModel.fromJson(json).toJson(), json);",
+	} {
+		if !strings.Contains(test, expect) {
+			t.Fatalf("expected model test to contain %q, got:\n%s", expect, test)
+		}
+	}
+}
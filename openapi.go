@@ -0,0 +1,895 @@
+// openapi.go
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// orderedMap is a YAML mapping that remembers the order of its keys, so the
+// generated code follows the order of the spec.
+type orderedMap[T any] struct {
+	keys   []string
+	values map[string]T
+}
+
+func (m *orderedMap[T]) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind != yaml.MappingNode {
+		return fmt.Errorf("line %d: expected a mapping", node.Line)
+	}
+	m.keys = nil
+	m.values = map[string]T{}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		var v T
+		if err := node.Content[i+1].Decode(&v); err != nil {
+			return err
+		}
+		key := node.Content[i].Value
+		if _, ok := m.values[key]; !ok {
+			m.keys = append(m.keys, key)
+		}
+		m.values[key] = v
+	}
+	return nil
+}
+
+type openAPIDoc struct {
+	OpenAPI    string                      `yaml:"openapi"`
+	Swagger    string                      `yaml:"swagger"`
+	Paths      orderedMap[openAPIPathItem] `yaml:"paths"`
+	Components struct {
+		Schemas       map[string]*openAPISchema      `yaml:"schemas"`
+		Parameters    map[string]*openAPIParameter   `yaml:"parameters"`
+		RequestBodies map[string]*openAPIRequestBody `yaml:"requestBodies"`
+		Responses     map[string]*openAPIResponse    `yaml:"responses"`
+	} `yaml:"components"`
+}
+
+type openAPIPathItem struct {
+	Parameters []*openAPIParameter `yaml:"parameters"`
+	Get        *openAPIOperation   `yaml:"get"`
+	Post       *openAPIOperation   `yaml:"post"`
+	Put        *openAPIOperation   `yaml:"put"`
+	Patch      *openAPIOperation   `yaml:"patch"`
+	Delete     *openAPIOperation   `yaml:"delete"`
+}
+
+type openAPIOperation struct {
+	OperationID string                       `yaml:"operationId"`
+	Tags        []string                     `yaml:"tags"`
+	Parameters  []*openAPIParameter          `yaml:"parameters"`
+	RequestBody *openAPIRequestBody          `yaml:"requestBody"`
+	Responses   orderedMap[*openAPIResponse] `yaml:"responses"`
+}
+
+type openAPIParameter struct {
+	Ref      string         `yaml:"$ref"`
+	Name     string         `yaml:"name"`
+	In       string         `yaml:"in"`
+	Required bool           `yaml:"required"`
+	Schema   *openAPISchema `yaml:"schema"`
+}
+
+type openAPIRequestBody struct {
+	Ref      string                       `yaml:"$ref"`
+	Required bool                         `yaml:"required"`
+	Content  orderedMap[openAPIMediaType] `yaml:"content"`
+}
+
+type openAPIResponse struct {
+	Ref     string                       `yaml:"$ref"`
+	Content orderedMap[openAPIMediaType] `yaml:"content"`
+}
+
+type openAPIMediaType struct {
+	Schema *openAPISchema `yaml:"schema"`
+}
+
+type openAPISchema struct {
+	Ref                  string                     `yaml:"$ref"`
+	Type                 yaml.Node                  `yaml:"type"`
+	Format               string                     `yaml:"format"`
+	Nullable             bool                       `yaml:"nullable"`
+	Items                *openAPISchema             `yaml:"items"`
+	Properties           orderedMap[*openAPISchema] `yaml:"properties"`
+	Required             []string                   `yaml:"required"`
+	AdditionalProperties yaml.Node                  `yaml:"additionalProperties"`
+	AllOf                []*openAPISchema           `yaml:"allOf"`
+	OneOf                []*openAPISchema           `yaml:"oneOf"`
+	AnyOf                []*openAPISchema           `yaml:"anyOf"`
+}
+
+// typeName returns the schema type and whether it allows null. OpenAPI 3.1
+// writes nullable types as a list such as [string, "null"].
+func (s *openAPISchema) typeName() (string, bool) {
+	switch s.Type.Kind {
+	case yaml.ScalarNode:
+		return s.Type.Value, s.Nullable
+	case yaml.SequenceNode:
+		name, nullable := "", s.Nullable
+		for _, n := range s.Type.Content {
+			if n.Value == "null" {
+				nullable = true
+			} else if name == "" {
+				name = n.Value
+			}
+		}
+		return name, nullable
+	}
+	return "", s.Nullable
+}
+
+// apiParam is one argument of a generated API method.
+type apiParam struct {
+	Name     string
+	Key      string // name on the wire
+	In       string // "path", "query" or "body"
+	Type     string // entity type
+	Required bool
+}
+
+// apiMethod is one API operation as a datasource, repository and usecase
+// method. Types are entity types; model types are derived from them.
+type apiMethod struct {
+	Name       string
+	Usecase    string
+	HTTPMethod string
+	Path       string
+	Params     []apiParam
+	Returns    string
+}
+
+// ArgType is the declared entity type of the parameter.
+func (p apiParam) ArgType() string { return p.argType(false) }
+
+// argType is the declared type of a parameter: optional ones are nullable.
+func (p apiParam) argType(model bool) string {
+	typ := p.Type
+	if model {
+		typ = modelType(typ)
+	}
+	if !p.Required && !strings.HasSuffix(typ, "?") {
+		typ += "?"
+	}
+	return typ
+}
+
+func (m apiMethod) signature(model bool) string {
+	var positional, named []string
+	for _, p := range m.Params {
+		if p.Required {
+			positional = append(positional, p.argType(model)+" "+p.Name)
+		} else {
+			named = append(named, p.argType(model)+" "+p.Name)
+		}
+	}
+	if len(named) > 0 {
+		positional = append(positional, "{"+strings.Join(named, ", ")+"}")
+	}
+	return strings.Join(positional, ", ")
+}
+
+// Signature is the parameter list with entity types.
+func (m apiMethod) Signature() string { return m.signature(false) }
+
+// ModelSignature is the parameter list with model types.
+func (m apiMethod) ModelSignature() string { return m.signature(true) }
+
+// ModelReturns is the return type with model types.
+func (m apiMethod) ModelReturns() string { return modelType(m.Returns) }
+
+// ParamsArgs passes the fields of a usecase Params object to the method.
+func (m apiMethod) ParamsArgs() string {
+	return m.args(func(p apiParam) string { return "params." + p.Name })
+}
+
+func (m apiMethod) args(value func(apiParam) string) string {
+	var positional, named []string
+	for _, p := range m.Params {
+		if p.Required {
+			positional = append(positional, value(p))
+		} else {
+			named = append(named, p.Name+": "+value(p))
+		}
+	}
+	return strings.Join(append(positional, named...), ", ")
+}
+
+// DataSourceBody is the body of the Dio implementation of the method.
+func (m apiMethod) DataSourceBody() []string {
+	path := m.Path
+	var query, data []string
+	for _, p := range m.Params {
+		typ := strings.TrimSuffix(modelType(p.Type), "?")
+		switch p.In {
+		case "path":
+			path = strings.ReplaceAll(path, "{"+p.Key+"}", "${"+p.Name+"}")
+		case "query":
+			entry := fmt.Sprintf("'%s': %s,", p.Key, toJSONExpr(typ, p.Name, 0))
+			if !p.Required || strings.HasSuffix(p.Type, "?") {
+				entry = "if (" + p.Name + " != null) " + entry
+			}
+			query = append(query, entry)
+		case "body":
+			data = append(data, "data: "+toJSONExpr(p.argType(true), p.Name, 0)+",")
+		}
+	}
+	path = simplifyInterpolation(path)
+
+	typeArg := "dynamic"
+	if m.Returns == "void" {
+		typeArg = "void"
+	}
+	call := "client." + m.HTTPMethod + "<" + typeArg + ">("
+	var lines []string
+	prefix := "final response = await "
+	if m.Returns == "void" {
+		prefix = "await "
+	}
+	if len(query) == 0 && len(data) == 0 {
+		lines = append(lines, prefix+call+"'"+path+"');")
+	} else {
+		lines = append(lines, prefix+call, "  '"+path+"',")
+		if len(query) > 0 {
+			lines = append(lines, "  queryParameters: {")
+			for _, q := range query {
+				lines = append(lines, "    "+q)
+			}
+			lines = append(lines, "  },")
+		}
+		for _, d := range data {
+			lines = append(lines, "  "+d)
+		}
+		lines = append(lines, ");")
+	}
+	if m.Returns != "void" {
+		lines = append(lines, "return "+fromJSONExpr(m.ModelReturns(), "response.data", 0)+";")
+	}
+	return lines
+}
+
+// RepositoryBody is the body of the repository implementation, which calls
+// the datasource field, maps between entities and models and wraps the
+// outcome in Ok.
+func (m apiMethod) RepositoryBody(dataSource string) []string {
+	call := dataSource + "." + m.Name + "(" + m.args(func(p apiParam) string {
+		return fromEntityExpr(p.argType(true), p.Name)
+	}) + ")"
+	switch {
+	case m.Returns == "void":
+		return []string{"await " + call + ";", "return const Ok(null);"}
+	case modelTypeNeedsMapping(m.ModelReturns()):
+		return []string{"final result = await " + call + ";", "return Ok(" + toEntityExpr(m.ModelReturns(), "result") + ");"}
+	default:
+		return []string{"return Ok(await " + call + ");"}
+	}
+}
+
+// simplifyInterpolation turns "${id}" into "$id" where the next character
+// cannot continue the identifier.
+func simplifyInterpolation(s string) string {
+	var b strings.Builder
+	for {
+		start := strings.Index(s, "${")
+		if start == -1 {
+			b.WriteString(s)
+			return b.String()
+		}
+		end := strings.Index(s[start:], "}")
+		if end == -1 {
+			b.WriteString(s)
+			return b.String()
+		}
+		end += start
+		b.WriteString(s[:start])
+		if end+1 < len(s) && isIdentChar(s[end+1]) {
+			b.WriteString(s[start : end+1])
+		} else {
+			b.WriteString("$" + s[start+2:end])
+		}
+		s = s[end+1:]
+	}
+}
+
+// modelType maps an entity type to the matching model type.
+func modelType(typ string) string {
+	if typ == "void" {
+		return typ
+	}
+	return modelFields([]dartField{{Type: typ}})[0].Type
+}
+
+// typeFields wraps types as fields, for helpers that work on fields.
+func typeFields(types ...string) []dartField {
+	fields := make([]dartField, len(types))
+	for i, t := range types {
+		fields[i] = dartField{Type: t}
+	}
+	return fields
+}
+
+// openAPIFeature is what a feature generated from an OpenAPI document needs.
+type openAPIFeature struct {
+	classes []inferredClass
+	methods []apiMethod
+}
+
+// openAPIGenerator turns schemas and operations into classes and methods.
+type openAPIGenerator struct {
+	doc     *openAPIDoc
+	classes []inferredClass
+	names   classNames
+	schemas map[string]string
+}
+
+// createFeatureFromOpenAPI scaffolds a feature whose models, datasource,
+// repository and usecases come from the operations of one tag of an OpenAPI
+// document. Without a tag every operation is used.
+func createFeatureFromOpenAPI(feature, specPath, tag string) {
+	data, err := os.ReadFile(specPath)
+	if err != nil {
+		failf("Failed to read %s: %v", specPath, err)
+		return
+	}
+	api, err := parseOpenAPI(data, tag)
+	if errors.Is(err, errNoTaggedOperations) {
+		failf("%s: %v", specPath, err)
+		return
+	}
+	if err != nil {
+		failf("Failed to read OpenAPI document %s: %v", specPath, err)
+		return
+	}
+	fmt.Printf("📖 Found %d operation(s) and %d schema(s) in %s\n", len(api.methods), len(api.classes), specPath)
+
+	createFeatureExcept(feature, map[string]bool{
+		"entity": true, "model": true, "usecase": true, "repository": true, "datasource": true,
+	})
+	createClasses(feature, api.classes)
+
+	dsName := scaffoldName("datasource", feature, "remote")
+	repoName := scaffoldName("repository", feature, feature)
+	dsFile := createAPIDatasource(feature, dsName, api.methods)
+	repoFile := createAPIRepository(feature, repoName, dsName, dsFile, api.methods)
+	for _, m := range api.methods {
+		createAPIUsecase(feature, repoName, repoFile, m)
+	}
+	if !pubspecHasDependency("dio") {
+		fmt.Println("⚠️  The generated datasource uses package:dio; add dio to pubspec.yaml")
+	}
+}
+
+// scaffoldName returns the configured default scaffold name of kind for
+// feature, or fallback when none is configured.
+func scaffoldName(kind, feature, fallback string) string {
+	for _, s := range cfg.Scaffolds {
+		if s.Kind != kind {
+			continue
+		}
+		if strings.Contains(s.Name, "%s") {
+			return fmt.Sprintf(s.Name, feature)
+		}
+		return s.Name
+	}
+	return fallback
+}
+
+func createAPIDatasource(feature, dsName string, methods []apiMethod) string {
+	dir := filepath.Join("lib", "features", feature, "data", "datasources")
+	changes.mkdirAll(dir)
+
+	file := filepath.Join(dir, dsName+cfg.Suffixes.Datasource+".dart")
+	var types []string
+	for _, m := range methods {
+		types = append(types, m.ModelReturns())
+		for _, p := range m.Params {
+			types = append(types, p.argType(true))
+		}
+	}
+	ensureCore()
+	imports := append(classImports(file, typeFields(types...), modelPath(feature)), coreImports(file, coreExceptionsFile)...)
+	sort.Strings(imports)
+	writeTemplate(file, "datasource.dart.tmpl", templateData{
+		Feature: feature,
+		Name:    dsName,
+		Methods: methods,
+		Imports: imports,
+	})
+	writeTest(feature, filepath.Join("data", "datasources", dsName+"_datasource_test.dart"), testStub("datasource "+dsName))
+	registerDatasource(feature, dsName, file, true)
+	return file
+}
+
+func createAPIRepository(feature, repoName, dsName, dsFile string, methods []apiMethod) string {
+	domainDir := filepath.Join("lib", "features", feature, "domain", "repositories")
+	dataDir := filepath.Join("lib", "features", feature, "data", "repositories")
+	changes.mkdirAll(domainDir)
+	changes.mkdirAll(dataDir)
+
+	domainFile := filepath.Join(domainDir, repoName+cfg.Suffixes.Repository+".dart")
+	dataFile := filepath.Join(dataDir, repoName+cfg.Suffixes.RepositoryImpl+".dart")
+
+	var entityTypes, convertedTypes []string
+	for _, m := range methods {
+		entityTypes = append(entityTypes, m.Returns)
+		for _, p := range m.Params {
+			entityTypes = append(entityTypes, p.Type)
+			if modelTypeNeedsMapping(p.argType(true)) {
+				convertedTypes = append(convertedTypes, p.argType(true))
+			}
+		}
+	}
+	ensureCore()
+	domainImports := append(classImports(domainFile, typeFields(entityTypes...), entityPath(feature)), coreImports(domainFile, coreResultFile)...)
+	sort.Strings(domainImports)
+	writeTemplate(domainFile, "repository.dart.tmpl", templateData{
+		Feature: feature,
+		Name:    repoName,
+		Methods: methods,
+		Imports: domainImports,
+	})
+
+	imports := append(classImports(dataFile, typeFields(entityTypes...), entityPath(feature)),
+		classImports(dataFile, typeFields(convertedTypes...), modelPath(feature))...)
+	imports = append(imports, dartRelImport(dataFile, dsFile), dartRelImport(dataFile, domainFile))
+	imports = append(imports, coreImports(dataFile, coreExceptionsFile, coreFailuresFile, coreResultFile)...)
+	sort.Strings(imports)
+	writeTemplate(dataFile, "repository_impl.dart.tmpl", templateData{
+		Feature:          feature,
+		Name:             repoName,
+		RepositoryImport: dartRelImport(dataFile, domainFile),
+		DataSource:       dsName,
+		Methods:          methods,
+		Imports:          imports,
+	})
+	writeTest(feature, filepath.Join("data", "repositories", repoName+"_repository_test.dart"), testStub("repository "+repoName))
+	registerRepository(feature, repoName, domainFile, dataFile, dsName)
+	return domainFile
+}
+
+func createAPIUsecase(feature, repoName, repoFile string, m apiMethod) {
+	dir := filepath.Join("lib", "features", feature, "domain", "usecases")
+	changes.mkdirAll(dir)
+
+	file := filepath.Join(dir, m.Usecase+cfg.Suffixes.Usecase+".dart")
+	types := []string{m.Returns}
+	for _, p := range m.Params {
+		types = append(types, p.Type)
+	}
+	ensureCore()
+	imports := append(classImports(file, typeFields(types...), entityPath(feature)), dartRelImport(file, repoFile))
+	imports = append(imports, coreImports(file, coreUsecaseFile, coreResultFile)...)
+	sort.Strings(imports)
+	writeTemplate(file, "usecase.dart.tmpl", templateData{
+		Feature:    feature,
+		Name:       m.Usecase,
+		Repository: repoName,
+		Method:     &m,
+		Imports:    imports,
+	})
+	writeTest(feature, filepath.Join("domain", "usecases", m.Usecase+"_usecase_test.dart"), testStub("usecase "+m.Usecase))
+	registerUsecase(feature, m.Usecase, file, repoName)
+}
+
+// entityPath locates the entity file of a class in feature.
+func entityPath(feature string) func(string) string {
+	return func(class string) string {
+		return filepath.Join("lib", "features", feature, "domain", "entities", snakeCase(class)+cfg.Suffixes.Entity+".dart")
+	}
+}
+
+// modelPath locates the model file of a model class in feature.
+func modelPath(feature string) func(string) string {
+	return func(class string) string {
+		return filepath.Join("lib", "features", feature, "data", "models", snakeCase(strings.TrimSuffix(class, "Model"))+cfg.Suffixes.Model+".dart")
+	}
+}
+
+// pubspecHasDependency reports whether pubspec.yaml lists the package. It
+// returns true when there is no readable pubspec, so nothing is reported.
+func pubspecHasDependency(name string) bool {
+	data, err := os.ReadFile("pubspec.yaml")
+	if err != nil {
+		return true
+	}
+	var pubspec struct {
+		Dependencies map[string]any `yaml:"dependencies"`
+	}
+	if err := yaml.Unmarshal(data, &pubspec); err != nil {
+		return true
+	}
+	_, ok := pubspec.Dependencies[name]
+	return ok
+}
+
+// errNoTaggedOperations is returned by parseOpenAPI when no operation has the
+// tag asked for.
+var errNoTaggedOperations = errors.New("no operations match the tag")
+
+// parseOpenAPI reads an OpenAPI 3 document (YAML or JSON) and collects the
+// operations tagged tag, plus every schema they use.
+func parseOpenAPI(data []byte, tag string) (openAPIFeature, error) {
+	var doc openAPIDoc
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return openAPIFeature{}, err
+	}
+	if doc.Swagger != "" || !strings.HasPrefix(doc.OpenAPI, "3.") {
+		return openAPIFeature{}, errors.New("only OpenAPI 3 documents are supported")
+	}
+
+	g := &openAPIGenerator{doc: &doc, names: classNames{}, schemas: map[string]string{}}
+	var methods []apiMethod
+	usedNames := map[string]bool{}
+	tags := map[string]bool{}
+	for _, path := range doc.Paths.keys {
+		item := doc.Paths.values[path]
+		for _, op := range []struct {
+			method string
+			op     *openAPIOperation
+		}{{"get", item.Get}, {"post", item.Post}, {"put", item.Put}, {"patch", item.Patch}, {"delete", item.Delete}} {
+			if op.op == nil {
+				continue
+			}
+			for _, t := range op.op.Tags {
+				tags[t] = true
+			}
+			if tag != "" && !hasTag(op.op.Tags, tag) {
+				continue
+			}
+			m, err := g.method(op.method, path, item.Parameters, op.op)
+			if err != nil {
+				return openAPIFeature{}, fmt.Errorf("%s %s: %w", strings.ToUpper(op.method), path, err)
+			}
+			base := m.Name
+			for i := 2; usedNames[m.Name]; i++ {
+				m.Name = fmt.Sprintf("%s%d", base, i)
+			}
+			usedNames[m.Name] = true
+			m.Usecase = snakeCase(m.Name)
+			methods = append(methods, m)
+		}
+	}
+	if len(methods) == 0 {
+		if tag == "" {
+			return openAPIFeature{}, errors.New("the document has no operations")
+		}
+		known := make([]string, 0, len(tags))
+		for t := range tags {
+			known = append(known, t)
+		}
+		if len(known) == 0 {
+			return openAPIFeature{}, fmt.Errorf("%w %q; the document has no tags", errNoTaggedOperations, tag)
+		}
+		sort.Strings(known)
+		return openAPIFeature{}, fmt.Errorf("%w %q; the document has tags: %s", errNoTaggedOperations, tag, strings.Join(known, ", "))
+	}
+	return openAPIFeature{classes: g.classes, methods: methods}, nil
+}
+
+func hasTag(tags []string, tag string) bool {
+	for _, t := range tags {
+		if strings.EqualFold(t, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// method converts one operation. Path parameters come first, then query
+// parameters, then the request body.
+func (g *openAPIGenerator) method(httpMethod, path string, shared []*openAPIParameter, op *openAPIOperation) (apiMethod, error) {
+	name := op.OperationID
+	if name == "" {
+		name = httpMethod + "_" + pathMethodName(path)
+	}
+	m := apiMethod{Name: jsonFieldName(name), HTTPMethod: httpMethod, Path: path}
+
+	params := map[string]*openAPIParameter{}
+	var order []string
+	for _, list := range [][]*openAPIParameter{shared, op.Parameters} {
+		for _, p := range list {
+			p, err := g.parameter(p)
+			if err != nil {
+				return m, err
+			}
+			key := p.In + ":" + p.Name
+			if _, ok := params[key]; !ok {
+				order = append(order, key)
+			}
+			params[key] = p
+		}
+	}
+	usedNames := map[string]bool{}
+	add := func(p apiParam) {
+		base := p.Name
+		for i := 2; usedNames[p.Name]; i++ {
+			p.Name = fmt.Sprintf("%s%d", base, i)
+		}
+		usedNames[p.Name] = true
+		m.Params = append(m.Params, p)
+	}
+	for _, in := range []string{"path", "query"} {
+		for _, key := range order {
+			p := params[key]
+			if p.In != in {
+				continue
+			}
+			typ, nullable := g.schemaType(p.Schema, snakeCase(p.Name), snakeCase(m.Name))
+			if nullable {
+				typ += "?"
+			}
+			add(apiParam{Name: jsonFieldName(p.Name), Key: p.Name, In: in, Type: typ, Required: p.Required || in == "path"})
+		}
+	}
+	for _, key := range order {
+		if in := params[key].In; in != "path" && in != "query" {
+			fmt.Printf("⚠️  %s %s: %s parameter %q is not generated\n", strings.ToUpper(httpMethod), path, in, params[key].Name)
+		}
+	}
+
+	if op.RequestBody != nil {
+		body, err := g.requestBody(op.RequestBody)
+		if err != nil {
+			return m, err
+		}
+		if schema := jsonSchema(body.Content); schema != nil {
+			typ, nullable := g.schemaType(schema, snakeCase(m.Name)+"_request", "")
+			if nullable {
+				typ += "?"
+			}
+			add(apiParam{Name: "body", Key: "body", In: "body", Type: typ, Required: body.Required})
+		}
+	}
+
+	m.Returns = "void"
+	for _, code := range op.Responses.keys {
+		if !strings.HasPrefix(code, "2") {
+			continue
+		}
+		resp, err := g.response(op.Responses.values[code])
+		if err != nil {
+			return m, err
+		}
+		if schema := jsonSchema(resp.Content); schema != nil {
+			typ, nullable := g.schemaType(schema, snakeCase(m.Name)+"_response", "")
+			if nullable {
+				typ += "?"
+			}
+			m.Returns = typ
+		}
+		break
+	}
+	return m, nil
+}
+
+// pathMethodName names an operation without an operationId after its path:
+// "/orders/{id}/items" becomes "orders_by_id_items".
+func pathMethodName(path string) string {
+	var parts []string
+	for _, seg := range strings.Split(path, "/") {
+		switch {
+		case seg == "":
+		case strings.HasPrefix(seg, "{") && strings.HasSuffix(seg, "}"):
+			parts = append(parts, "by", snakeCase(seg[1:len(seg)-1]))
+		default:
+			parts = append(parts, snakeCase(seg))
+		}
+	}
+	return strings.Join(parts, "_")
+}
+
+// jsonSchema returns the schema of the JSON content of a body or response.
+func jsonSchema(content orderedMap[openAPIMediaType]) *openAPISchema {
+	if media, ok := content.values["application/json"]; ok {
+		return media.Schema
+	}
+	for _, key := range content.keys {
+		if strings.Contains(key, "json") {
+			return content.values[key].Schema
+		}
+	}
+	return nil
+}
+
+func refName(ref, section string) (string, error) {
+	prefix := "#/components/" + section + "/"
+	if !strings.HasPrefix(ref, prefix) {
+		return "", fmt.Errorf("unsupported reference %q", ref)
+	}
+	return strings.TrimPrefix(ref, prefix), nil
+}
+
+func (g *openAPIGenerator) parameter(p *openAPIParameter) (*openAPIParameter, error) {
+	for seen := 0; p != nil && p.Ref != ""; seen++ {
+		name, err := refName(p.Ref, "parameters")
+		if err != nil || seen > 10 {
+			return nil, fmt.Errorf("cannot resolve %q", p.Ref)
+		}
+		p = g.doc.Components.Parameters[name]
+	}
+	if p == nil {
+		return nil, errors.New("missing parameter definition")
+	}
+	return p, nil
+}
+
+func (g *openAPIGenerator) requestBody(b *openAPIRequestBody) (*openAPIRequestBody, error) {
+	for seen := 0; b != nil && b.Ref != ""; seen++ {
+		name, err := refName(b.Ref, "requestBodies")
+		if err != nil || seen > 10 {
+			return nil, fmt.Errorf("cannot resolve %q", b.Ref)
+		}
+		b = g.doc.Components.RequestBodies[name]
+	}
+	if b == nil {
+		return nil, errors.New("missing request body definition")
+	}
+	return b, nil
+}
+
+func (g *openAPIGenerator) response(r *openAPIResponse) (*openAPIResponse, error) {
+	for seen := 0; r != nil && r.Ref != ""; seen++ {
+		name, err := refName(r.Ref, "responses")
+		if err != nil || seen > 10 {
+			return nil, fmt.Errorf("cannot resolve %q", r.Ref)
+		}
+		r = g.doc.Components.Responses[name]
+	}
+	if r == nil {
+		return nil, errors.New("missing response definition")
+	}
+	return r, nil
+}
+
+// schemaType returns the Dart entity type of a schema and whether it allows
+// null. Inline objects become classes named after hint; component schemas
+// become classes named after the schema.
+func (g *openAPIGenerator) schemaType(s *openAPISchema, hint, parent string) (string, bool) {
+	if s == nil {
+		return "Object", true
+	}
+	if s.Ref != "" {
+		name, err := refName(s.Ref, "schemas")
+		if err != nil {
+			return "Object", true
+		}
+		return g.componentType(name), s.Nullable
+	}
+	typ, nullable := s.typeName()
+	switch {
+	case len(s.AllOf) == 1:
+		t, n := g.schemaType(s.AllOf[0], hint, parent)
+		return t, n || nullable
+	case len(s.AllOf) > 1:
+		return g.objectClass(s, hint, parent), nullable
+	case len(s.OneOf) > 0 || len(s.AnyOf) > 0:
+		return "Object", true
+	case typ == "object" || (typ == "" && len(s.Properties.keys) > 0):
+		if len(s.Properties.keys) > 0 {
+			return g.objectClass(s, hint, parent), nullable
+		}
+		if s.AdditionalProperties.Kind == yaml.MappingNode {
+			var value openAPISchema
+			if err := s.AdditionalProperties.Decode(&value); err == nil {
+				t, n := g.schemaType(&value, singular(hint), parent)
+				if n || t == "Object" {
+					t += "?"
+				}
+				return "Map<String, " + t + ">", nullable
+			}
+		}
+		return "Map<String, dynamic>", nullable
+	case typ == "array":
+		item, n := g.schemaType(s.Items, singular(hint), parent)
+		if n || item == "Object" {
+			item += "?"
+		}
+		return "List<" + item + ">", nullable
+	case typ == "string" && (s.Format == "date-time" || s.Format == "date"):
+		return "DateTime", nullable
+	case typ == "string":
+		return "String", nullable
+	case typ == "integer":
+		return "int", nullable
+	case typ == "number":
+		return "double", nullable
+	case typ == "boolean":
+		return "bool", nullable
+	default:
+		return "Object", true
+	}
+}
+
+// componentType returns the Dart type of a component schema, generating its
+// class the first time it is used.
+func (g *openAPIGenerator) componentType(name string) string {
+	if typ, ok := g.schemas[name]; ok {
+		return typ
+	}
+	s := g.doc.Components.Schemas[name]
+	if s == nil {
+		return "Object"
+	}
+	typ, _ := s.typeName()
+	if len(s.Properties.keys) > 0 || len(s.AllOf) > 1 || (typ == "object" && s.AdditionalProperties.Kind != yaml.MappingNode) {
+		class := g.names.reserve(snakeCase(name), "")
+		// Registered before the fields are read, so recursive schemas
+		// refer to the class instead of recursing forever.
+		g.schemas[name] = pascalCase(class)
+		g.addClass(s, class)
+		return pascalCase(class)
+	}
+	g.schemas[name] = "Object"
+	t, _ := g.schemaType(s, snakeCase(name), "")
+	g.schemas[name] = t
+	return t
+}
+
+// objectClass generates a class for an inline object schema.
+func (g *openAPIGenerator) objectClass(s *openAPISchema, hint, parent string) string {
+	class := g.names.reserve(hint, parent)
+	g.addClass(s, class)
+	return pascalCase(class)
+}
+
+// addClass reads the properties of an object schema (merging allOf parts)
+// into the class. Classes are added after their fields, so nested classes
+// come first.
+func (g *openAPIGenerator) addClass(s *openAPISchema, class string) {
+	var keys []string
+	props := map[string]*openAPISchema{}
+	required := map[string]bool{}
+	var collect func(s *openAPISchema, depth int)
+	collect = func(s *openAPISchema, depth int) {
+		if s == nil || depth > 10 {
+			return
+		}
+		if s.Ref != "" {
+			if name, err := refName(s.Ref, "schemas"); err == nil {
+				collect(g.doc.Components.Schemas[name], depth+1)
+			}
+			return
+		}
+		for _, part := range s.AllOf {
+			collect(part, depth+1)
+		}
+		for _, key := range s.Properties.keys {
+			if _, ok := props[key]; !ok {
+				keys = append(keys, key)
+			}
+			props[key] = s.Properties.values[key]
+		}
+		for _, r := range s.Required {
+			required[r] = true
+		}
+	}
+	collect(s, 0)
+
+	fields := make([]dartField, 0, len(keys))
+	usedNames := map[string]bool{}
+	for _, key := range keys {
+		typ, nullable := g.schemaType(props[key], snakeCase(jsonFieldName(key)), class)
+		if nullable || !required[key] || typ == "Object" {
+			typ += "?"
+		}
+		field := dartField{Name: jsonFieldName(key), Type: typ}
+		for i := 2; usedNames[field.Name]; i++ {
+			field.Name = fmt.Sprintf("%s%d", jsonFieldName(key), i)
+		}
+		usedNames[field.Name] = true
+		if field.Name != key {
+			field.Key = key
+		}
+		fields = append(fields, field)
+	}
+	if len(fields) == 0 {
+		fields = defaultEntityFields
+	}
+	g.classes = append(g.classes, inferredClass{name: class, fields: fields})
+}
@@ -0,0 +1,209 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+const testOpenAPISpec = `openapi: 3.0.3
+info: {title: Shop, version: "1"}
+paths:
+  /orders:
+    get:
+      operationId: listOrders
+      tags: [orders]
+      parameters:
+        - {name: status, in: query, schema: {type: string}}
+        - {name: page_size, in: query, required: true, schema: {type: integer}}
+      responses:
+        "200":
+          description: ok
+          content:
+            application/json:
+              schema: {type: array, items: {$ref: '#/components/schemas/Order'}}
+    post:
+      operationId: createOrder
+      tags: [orders]
+      requestBody:
+        required: true
+        content:
+          application/json:
+            schema: {$ref: '#/components/schemas/Order'}
+      responses:
+        "201":
+          description: created
+          content:
+            application/json:
+              schema: {$ref: '#/components/schemas/Order'}
+  /orders/{orderId}:
+    parameters:
+      - $ref: '#/components/parameters/OrderId'
+    delete:
+      tags: [orders]
+      responses:
+        "204": {description: gone}
+  /users:
+    get:
+      operationId: listUsers
+      tags: [users]
+      responses:
+        "200": {description: ok}
+components:
+  parameters:
+    OrderId: {name: orderId, in: path, required: true, schema: {type: integer}}
+  schemas:
+    Order:
+      type: object
+      required: [id, items]
+      properties:
+        id: {type: integer}
+        placed_at: {type: string, format: date-time}
+        items: {type: array, items: {$ref: '#/components/schemas/LineItem'}}
+        parent: {$ref: '#/components/schemas/Order'}
+    LineItem:
+      type: object
+      required: [sku]
+      properties:
+        sku: {type: string}
+`
+
+func TestParseOpenAPI(t *testing.T) {
+	api, err := parseOpenAPI([]byte(testOpenAPISpec), "orders")
+	if err != nil {
+		t.Fatalf("parseOpenAPI failed: %v", err)
+	}
+
+	wantMethods := []apiMethod{
+		{Name: "listOrders", Usecase: "list_orders", HTTPMethod: "get", Path: "/orders", Returns: "List<Order>", Params: []apiParam{
+			{Name: "status", Key: "status", In: "query", Type: "String"},
+			{Name: "pageSize", Key: "page_size", In: "query", Type: "int", Required: true},
+		}},
+		{Name: "createOrder", Usecase: "create_order", HTTPMethod: "post", Path: "/orders", Returns: "Order", Params: []apiParam{
+			{Name: "body", Key: "body", In: "body", Type: "Order", Required: true},
+		}},
+		{Name: "deleteOrdersByOrderId", Usecase: "delete_orders_by_order_id", HTTPMethod: "delete", Path: "/orders/{orderId}", Returns: "void", Params: []apiParam{
+			{Name: "orderId", Key: "orderId", In: "path", Type: "int", Required: true},
+		}},
+	}
+	if !reflect.DeepEqual(api.methods, wantMethods) {
+		t.Fatalf("unexpected methods:\n got  %+v\n want %+v", api.methods, wantMethods)
+	}
+
+	wantClasses := []inferredClass{
+		{name: "line_item", fields: []dartField{{Name: "sku", Type: "String"}}},
+		{name: "order", fields: []dartField{
+			{Name: "id", Type: "int"},
+			{Name: "placedAt", Type: "DateTime?", Key: "placed_at"},
+			{Name: "items", Type: "List<LineItem>"},
+			{Name: "parent", Type: "Order?"},
+		}},
+	}
+	if !reflect.DeepEqual(api.classes, wantClasses) {
+		t.Fatalf("unexpected classes:\n got  %+v\n want %+v", api.classes, wantClasses)
+	}
+}
+
+func TestParseOpenAPIErrors(t *testing.T) {
+	if _, err := parseOpenAPI([]byte(testOpenAPISpec), "billing"); !errors.Is(err, errNoTaggedOperations) || !strings.Contains(err.Error(), "tags: orders, users") {
+		t.Fatalf("expected unknown tag error listing tags, got %v", err)
+	}
+	if _, err := parseOpenAPI([]byte("swagger: \"2.0\"\n"), ""); err == nil {
+		t.Fatalf("expected Swagger 2 documents to be rejected")
+	}
+}
+
+func TestNewFeatureFromOpenAPIUnknownTag(t *testing.T) {
+	withTempDir(t)
+	mustWriteFile(t, "spec.yaml", testOpenAPISpec)
+
+	out, code := runMainCode(t, "new", "feature", "billing", "--openapi", "spec.yaml", "--tag", "billing")
+	want := `❌ spec.yaml: no operations match the tag "billing"; the document has tags: orders, users`
+	if code != 1 || !strings.Contains(out, want) || strings.Contains(out, "Failed to read") {
+		t.Fatalf("expected the unknown tag to be reported, got code %d:\n%s", code, out)
+	}
+}
+
+func TestRepositoryBodyUsesPromotedOptionalBody(t *testing.T) {
+	m := apiMethod{Name: "createOrder", Returns: "void", Params: []apiParam{{Name: "body", In: "body", Type: "Order"}}}
+	got := strings.Join(m.RepositoryBody("remoteDataSource"), "\n")
+	if want := "await remoteDataSource.createOrder(body: body == null ? null : OrderModel.fromEntity(body));"; !strings.HasPrefix(got, want) {
+		t.Fatalf("expected %q, got:\n%s", want, got)
+	}
+}
+
+func TestNewFeatureFromOpenAPI(t *testing.T) {
+	withTempDir(t)
+	if err := os.WriteFile("spec.yaml", []byte(testOpenAPISpec), 0644); err != nil {
+		t.Fatalf("write spec failed: %v", err)
+	}
+	_ = runMain(t, "new", "feature", "orders", "--openapi", "spec.yaml", "--tag", "orders")
+
+	base := filepath.Join("lib", "features", "orders")
+	mustNotExist(t, filepath.Join(base, "domain", "usecases", "example.dart"))
+	mustNotExist(t, filepath.Join(base, "domain", "entities", "orders.dart"))
+	mustExist(t, filepath.Join(base, "presentation", "pages", "orders_page.dart"))
+	mustExist(t, filepath.Join(base, "data", "models", "line_item_model.dart"))
+
+	ds := mustReadFile(t, filepath.Join(base, "data", "datasources", "remote_datasource.dart"))
+	for _, expect := range []string{
+		"import 'package:dio/dio.dart';",
+		"import '../models/order_model.dart';",
+		"  Future<List<OrderModel>> listOrders(int pageSize, {String? status});",
+		"import '../../../../core/error/exceptions.dart';",
+		"          if (status != null) 'status': status,",
+		"          'page_size': pageSize,",
+		"      return (response.data as List<dynamic>).map((e) => OrderModel.fromJson(e as Map<String, dynamic>)).toList();",
+		"        data: body.toJson(),",
+		"      await client.delete<void>('/orders/$orderId');",
+		"    } on DioException catch (e) {\n      throw ServerException(e.message ?? 'Request failed', statusCode: e.response?.statusCode);\n    }",
+	} {
+		if !strings.Contains(ds, expect) {
+			t.Fatalf("expected datasource to contain %q, got:\n%s", expect, ds)
+		}
+	}
+
+	repo := mustReadFile(t, filepath.Join(base, "domain", "repositories", "orders_repository.dart"))
+	if !strings.Contains(repo, "  Future<Result<Order>> createOrder(Order body);") {
+		t.Fatalf("expected repository method, got:\n%s", repo)
+	}
+	impl := mustReadFile(t, filepath.Join(base, "data", "repositories", "orders_repository_impl.dart"))
+	for _, expect := range []string{
+		"import '../datasources/remote_datasource.dart';",
+		"  OrdersRepositoryImpl(this.remoteDataSource);",
+		"      final result = await remoteDataSource.createOrder(OrderModel.fromEntity(body));",
+		"      return Ok(result.toEntity());",
+		"      await remoteDataSource.deleteOrdersByOrderId(orderId);\n      return const Ok(null);",
+		"    } on ServerException catch (e) {\n      return Err(ServerFailure(e.message, statusCode: e.statusCode));\n    }",
+	} {
+		if !strings.Contains(impl, expect) {
+			t.Fatalf("expected repository impl to contain %q, got:\n%s", expect, impl)
+		}
+	}
+
+	var uris []string
+	for _, d := range parseDirectives(impl) {
+		uris = append(uris, d.URI())
+	}
+	if len(uris) < 2 || !strings.HasPrefix(uris[0], "../../../../core/") || !sort.StringsAreSorted(uris) {
+		t.Fatalf("expected sorted repository impl imports, got %v", uris)
+	}
+
+	usecase := mustReadFile(t, filepath.Join(base, "domain", "usecases", "list_orders.dart"))
+	for _, expect := range []string{
+		"import '../repositories/orders_repository.dart';",
+		"class ListOrders extends UseCase<List<Order>, ListOrdersParams> {",
+		"  Future<Result<List<Order>>> call(ListOrdersParams params) {",
+		"    return repository.listOrders(params.pageSize, status: params.status);",
+		"  final String? status;",
+	} {
+		if !strings.Contains(usecase, expect) {
+			t.Fatalf("expected usecase to contain %q, got:\n%s", expect, usecase)
+		}
+	}
+	mustNotExist(t, filepath.Join(base, "domain", "usecases", "list_users.dart"))
+}
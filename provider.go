@@ -0,0 +1,167 @@
+// provider.go
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Provider kinds accepted by new provider --kind.
+const (
+	providerFunctional    = "functional"
+	providerNotifier      = "notifier"
+	providerAsyncNotifier = "async_notifier"
+	providerFuture        = "future"
+	providerStream        = "stream"
+)
+
+var providerKinds = []string{providerFunctional, providerNotifier, providerAsyncNotifier, providerFuture, providerStream}
+
+// parseProviderKind validates a --kind value. Dashes may stand in for
+// underscores ("async-notifier"); an empty value means functional.
+func parseProviderKind(s string) (string, error) {
+	kind := strings.ReplaceAll(strings.ToLower(strings.TrimSpace(s)), "-", "_")
+	if kind == "" {
+		return providerFunctional, nil
+	}
+	for _, k := range providerKinds {
+		if k == kind {
+			return kind, nil
+		}
+	}
+	return "", fmt.Errorf("unknown provider kind %q (want %s)", s, strings.Join(providerKinds, ", "))
+}
+
+// providerTemplates returns the provider and test templates of a kind. The
+// functional kind keeps the original provider.dart.tmpl name.
+func providerTemplates(kind string) (string, string) {
+	if kind == providerFunctional {
+		return "provider.dart.tmpl", "provider_test.dart.tmpl"
+	}
+	return "provider_" + kind + ".dart.tmpl", "provider_" + kind + "_test.dart.tmpl"
+}
+
+// providerVar names the variable riverpod_generator emits for a provider:
+// notifier classes are named "<Name>Notifier", so their provider is
+// "<name>NotifierProvider".
+func providerVar(providerName, kind string) string {
+	if kind == providerNotifier || kind == providerAsyncNotifier {
+		return camelCase(providerName) + "NotifierProvider"
+	}
+	return camelCase(providerName) + "Provider"
+}
+
+var (
+	providerClassRe      = regexp.MustCompile(`(?m)^class\s+\w+\s+extends\s+_\$\w+`)
+	providerAsyncBuild   = regexp.MustCompile(`(?m)^\s*(?:Future|FutureOr|Stream)<.*>\s+build\(`)
+	providerFunctionRe   = regexp.MustCompile(`(?m)^(Future|FutureOr|Stream)<.*>\s+\w+\(\s*Ref\b`)
+	usecaseCallSignature = regexp.MustCompile(`(?m)^\s*Future<(.+)>\s+call\(([^)]*)\)`)
+)
+
+// providerKindOf detects the kind of an existing provider file, so pages
+// render a UI that fits it. Anything unrecognised is treated as functional.
+func providerKindOf(content string) string {
+	if providerClassRe.MatchString(content) {
+		if providerAsyncBuild.MatchString(content) {
+			return providerAsyncNotifier
+		}
+		return providerNotifier
+	}
+	if m := providerFunctionRe.FindStringSubmatch(content); m != nil {
+		if m[1] == "Stream" {
+			return providerStream
+		}
+		return providerFuture
+	}
+	return providerFunctional
+}
+
+func createProvider(feature, providerName, kind, usecaseName string) {
+	dir := filepath.Join("lib", "features", feature, "presentation", "providers")
+	changes.mkdirAll(dir)
+
+	file := filepath.Join(dir, providerName+cfg.Suffixes.Provider+".dart")
+	data := templateData{
+		Feature:     feature,
+		Name:        providerName,
+		Kind:        kind,
+		PartFile:    providerName + cfg.Suffixes.Provider + ".g.dart",
+		ProviderVar: providerVar(providerName, kind),
+		Result:      "int",
+	}
+	if usecaseName != "" && !providerUsecase(feature, file, usecaseName, &data) {
+		return
+	}
+	providerTmpl, testTmpl := providerTemplates(kind)
+	writeTemplate(file, providerTmpl, data)
+
+	var ok bool
+	if data.ProviderImport, ok = testImport(file); !ok {
+		return
+	}
+	content, err := renderTemplate(testTmpl, data)
+	if err != nil {
+		failf("Failed rendering provider test: %v", err)
+		return
+	}
+	writeTest(feature, filepath.Join("presentation", "providers", providerName+"_provider_test.dart"), content)
+}
+
+// providerUsecase points an AsyncNotifier at a usecase of the feature: the
+// state takes the usecase's result type and the usecase is looked up in the
+// injection container.
+func providerUsecase(feature, file, usecaseName string, data *templateData) bool {
+	usecaseFile := filepath.Join("lib", "features", feature, "domain", "usecases", usecaseName+cfg.Suffixes.Usecase+".dart")
+	if !changes.exists(usecaseFile) {
+		failf("Usecase %s not found; create it with: new usecase %s %s", usecaseFile, feature, usecaseName)
+		return false
+	}
+	content, err := changes.readFile(usecaseFile)
+	if err != nil {
+		failf("Failed to read %s: %v", usecaseFile, err)
+		return false
+	}
+	m := usecaseCallSignature.FindStringSubmatch(string(content))
+	if m == nil {
+		failf("Could not find the call method of %s", usecaseFile)
+		return false
+	}
+	// usecases built on the core UseCase take NoParams and return a Result
+	result, args, unwrap := strings.TrimSpace(m[1]), "", ""
+	switch params := strings.Fields(m[2]); {
+	case len(params) == 0:
+	case params[0] == "NoParams":
+		args = "const NoParams()"
+	default:
+		failf("Usecase %s takes %s; an async_notifier can only call a usecase without params", pascalCase(usecaseName), params[0])
+		return false
+	}
+	if strings.HasPrefix(result, "Result<") && strings.HasSuffix(result, ">") {
+		result = strings.TrimSuffix(strings.TrimPrefix(result, "Result<"), ">")
+		unwrap = ".getOrThrow()"
+	}
+
+	class := pascalCase(usecaseName)
+	lookup := fmt.Sprintf("sl<%s>()", class)
+	if cfg.DI == diRiverpod {
+		lookup = fmt.Sprintf("ref.watch(%s)", diProviderName(feature, usecaseName, "UsecaseProvider"))
+	}
+	data.Usecase = usecaseName
+	data.Result = result
+	data.UsecaseCall = fmt.Sprintf("%s.call(%s)", lookup, args)
+	if unwrap != "" {
+		data.UsecaseCall = "(await " + data.UsecaseCall + ")" + unwrap
+	}
+	imports := append(classImports(file, typeFields(data.Result), entityPath(feature)),
+		dartRelImport(file, usecaseFile),
+		dartRelImport(file, cfg.Paths.InjectionContainer))
+	if args != "" {
+		imports = append(imports, dartRelImport(file, coreUsecaseFile))
+	}
+	sort.Strings(imports)
+	data.Imports = imports
+	return true
+}
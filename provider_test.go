@@ -0,0 +1,103 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestNewProviderKinds(t *testing.T) {
+	withTempDir(t)
+	providers := filepath.Join("lib", "features", "orders", "presentation", "providers")
+	tests := filepath.Join("test", "features", "orders", "presentation", "providers")
+	cases := []struct {
+		kind     string
+		provider []string
+		test     string
+	}{
+		{kind: "functional", provider: []string{"@riverpod\nint plain(Ref ref) => 0;"}, test: "expect(container.read(plainProvider), 0);"},
+		{kind: "notifier", provider: []string{"class CounterState {", "class CounterNotifier extends _$CounterNotifier {", "CounterState build() => const CounterState();"}, test: "container.read(counterNotifierProvider.notifier).increment();"},
+		{kind: "async-notifier", provider: []string{"Future<int> build() async {", "state = await AsyncValue.guard(build);"}, test: "expect(await container.read(loaderNotifierProvider.future), 0);"},
+		{kind: "future", provider: []string{"Future<int> total(Ref ref) async {"}, test: "expect(await container.read(totalProvider.future), 0);"},
+		{kind: "stream", provider: []string{"Stream<int> ticks(Ref ref) async* {"}, test: "expect(await container.read(ticksProvider.future), 0);"},
+	}
+	names := map[string]string{"functional": "plain", "notifier": "counter", "async-notifier": "loader", "future": "total", "stream": "ticks"}
+	for _, tc := range cases {
+		name := names[tc.kind]
+		_ = runMain(t, "new", "provider", "orders", name, "--kind", tc.kind)
+		content := mustReadFile(t, filepath.Join(providers, name+"_provider.dart"))
+		for _, expect := range tc.provider {
+			if !strings.Contains(content, expect) {
+				t.Fatalf("%s provider should contain %q, got:\n%s", tc.kind, expect, content)
+			}
+		}
+		test := mustReadFile(t, filepath.Join(tests, name+"_provider_test.dart"))
+		if !strings.Contains(test, "final container = ProviderContainer();") || !strings.Contains(test, tc.test) {
+			t.Fatalf("%s provider test should use ProviderContainer and contain %q, got:\n%s", tc.kind, tc.test, test)
+		}
+	}
+
+	pages := map[string]string{
+		"plain":   "body: Center(),",
+		"counter": "onPressed: () => ref.read(counterNotifierProvider.notifier).increment(),",
+		"loader":  "body: state.when(",
+		"total":   "loading: () => const Center(child: CircularProgressIndicator()),",
+		"ticks":   "final state = ref.watch(ticksProvider);",
+	}
+	for name, expect := range pages {
+		_ = runMain(t, "new", "page", "orders", name)
+		content := mustReadFile(t, filepath.Join("lib", "features", "orders", "presentation", "pages", name+"_page.dart"))
+		if !strings.Contains(content, expect) {
+			t.Fatalf("page %s should contain %q, got:\n%s", name, expect, content)
+		}
+	}
+}
+
+func TestAsyncNotifierCallsUsecase(t *testing.T) {
+	withTempDir(t)
+	_ = runMain(t, "new", "feature", "orders")
+	_ = runMain(t, "new", "usecase", "orders", "get_orders", "--repo", "orders", "--returns", "List<Orders>")
+	out := runMain(t, "new", "provider", "orders", "orders_list", "--kind", "async_notifier", "--usecase", "get_orders")
+	if strings.Contains(out, "❌") {
+		t.Fatalf("unexpected failure:\n%s", out)
+	}
+
+	content := mustReadFile(t, filepath.Join("lib", "features", "orders", "presentation", "providers", "orders_list_provider.dart"))
+	for _, expect := range []string{
+		"import '../../../../core/usecases/usecase.dart';",
+		"import '../../../../injection_container.dart';",
+		"import '../../domain/entities/orders.dart';",
+		"import '../../domain/usecases/get_orders.dart';",
+		"Future<List<Orders>> build() async {\n    return (await sl<GetOrders>().call(const NoParams())).getOrThrow();\n  }",
+	} {
+		if !strings.Contains(content, expect) {
+			t.Fatalf("expected provider to contain %q, got:\n%s", expect, content)
+		}
+	}
+	test := mustReadFile(t, filepath.Join("test", "features", "orders", "presentation", "providers", "orders_list_provider_test.dart"))
+	if !strings.Contains(test, "expect(container.read(ordersListNotifierProvider).isLoading, isTrue);") {
+		t.Fatalf("unexpected provider test:\n%s", test)
+	}
+
+	_ = runMain(t, "new", "usecase", "orders", "get_order", "--repo", "orders", "--params", "id:int", "--returns", "Orders")
+	out, code := runMainCode(t, "new", "provider", "orders", "order", "--kind", "async_notifier", "--usecase", "get_order")
+	if code == 0 || !strings.Contains(out, "can only call a usecase without params") {
+		t.Fatalf("expected a usecase with params to be rejected, got %d:\n%s", code, out)
+	}
+	mustNotExist(t, filepath.Join("lib", "features", "orders", "presentation", "providers", "order_provider.dart"))
+}
+
+func TestAsyncNotifierUsesRiverpodContainer(t *testing.T) {
+	withTempDir(t)
+	if err := os.WriteFile(".farch.yaml", []byte("di: riverpod\n"), 0644); err != nil {
+		t.Fatalf("write config failed: %v", err)
+	}
+	_ = runMain(t, "new", "feature", "orders")
+	_ = runMain(t, "new", "provider", "orders", "example", "--kind", "async_notifier", "--usecase", "example")
+
+	content := mustReadFile(t, filepath.Join("lib", "features", "orders", "presentation", "providers", "example_provider.dart"))
+	if !strings.Contains(content, "Future<void> build() async {\n    return (await ref.watch(ordersExampleUsecaseProvider).call(const NoParams())).getOrThrow();") {
+		t.Fatalf("expected the usecase to come from the riverpod container, got:\n%s", content)
+	}
+}
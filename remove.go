@@ -0,0 +1,232 @@
+// remove.go
+package main
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// removeFeature deletes a feature's lib/ and test/ trees and strips the
+// imports, routes and page constants registered for its pages. Files edited
+// since generation are only deleted with force.
+func removeFeature(feature string, force bool) {
+	roots := []string{
+		filepath.Join("lib", "features", feature),
+		filepath.Join("test", "features", feature),
+	}
+
+	var files, dirs []string
+	for _, root := range roots {
+		f, d, err := collectTree(root)
+		if err != nil {
+			failf("Failed to read %s: %v", root, err)
+			return
+		}
+		files = append(files, f...)
+		dirs = append(dirs, d...)
+	}
+	if len(files) == 0 && len(dirs) == 0 {
+		failf("Feature %s not found under lib/features or test/features", feature)
+		return
+	}
+
+	pages := featurePages(feature)
+	if !removeFiles(files, force) {
+		return
+	}
+	for i := len(dirs) - 1; i >= 0; i-- {
+		changes.removeDir(dirs[i])
+	}
+	for _, page := range pages {
+		unregisterPage(feature, page)
+	}
+	unregisterShell(feature)
+	unregisterFeature(feature)
+	fmt.Printf("🎯 Removed feature %s\n", feature)
+}
+
+// removePage deletes a single page and its test and strips its import, route
+// and page constant.
+func removePage(feature, pageName string, force bool) {
+	pageFile := pageFilePath(feature, pageName)
+	if !changes.exists(pageFile) {
+		failf("Page %s not found", pageFile)
+		return
+	}
+
+	if data, err := changes.readFile(cfg.Paths.Router); err == nil {
+		if n := nestedRouteCount(string(data), feature, pageName); n > 0 {
+			failf("Page %s has %d nested route(s); remove its child pages first", pageName, n)
+			return
+		}
+	}
+
+	files := []string{pageFile}
+	testFile := filepath.Join("test", "features", feature, "presentation", "pages", pageName+"_page_test.dart")
+	if changes.exists(testFile) {
+		files = append(files, testFile)
+	}
+	if !removeFiles(files, force) {
+		return
+	}
+	unregisterPage(feature, pageName)
+}
+
+// removeFiles stages the deletion of files. Without force it refuses, and
+// deletes nothing, when any file differs from what farch generated.
+func removeFiles(files []string, force bool) bool {
+	m, err := loadManifest()
+	if err != nil {
+		failf("Failed to read %s: %v", manifestFile, err)
+		return false
+	}
+
+	if !force {
+		var edited []string
+		for _, path := range files {
+			if !m.pristine(path) {
+				edited = append(edited, path)
+			}
+		}
+		if len(edited) > 0 {
+			failf("Refusing to delete %d file(s) edited since generation or not created by farch (use --force)", len(edited))
+			for _, path := range edited {
+				fmt.Printf("   %s\n", filepath.ToSlash(path))
+			}
+			return false
+		}
+	}
+
+	for _, path := range files {
+		if err := changes.remove(path); err != nil {
+			failf("Failed to delete %s: %v", path, err)
+			return false
+		}
+		fmt.Printf("🗑️  Deleted %s\n", path)
+	}
+	return true
+}
+
+// collectTree lists the files and directories under root, parents before
+// children. A missing root yields nothing.
+func collectTree(root string) (files, dirs []string, err error) {
+	if _, statErr := os.Stat(root); os.IsNotExist(statErr) {
+		return nil, nil, nil
+	}
+	err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			dirs = append(dirs, path)
+		} else {
+			files = append(files, path)
+		}
+		return nil
+	})
+	return files, dirs, err
+}
+
+// featurePages returns the page names of a feature, derived from the
+// *_page.dart files in its presentation/pages folder.
+func featurePages(feature string) []string {
+	dir := filepath.Join("lib", "features", feature, "presentation", "pages")
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	suffix := cfg.Suffixes.Page + ".dart"
+	var pages []string
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasSuffix(name, suffix) || isGeneratedOutput(name) {
+			continue
+		}
+		if !changes.exists(filepath.Join(dir, name)) {
+			continue
+		}
+		pages = append(pages, strings.TrimSuffix(name, suffix))
+	}
+	sort.Strings(pages)
+	return pages
+}
+
+// unregisterPage strips what appendRoute and addPageConstant inserted for a
+// page. With page route names, the route and constant stay when another
+// feature still has a page of the same name, since they share them.
+func unregisterPage(feature, pageName string) {
+	constName := pageConstName(feature, pageName)
+	class := pageRouteClass(feature, pageName)
+	shared := ""
+	if cfg.RouteNames == routeNamesPage {
+		shared = pageOwner(feature, pageName)
+	}
+
+	routerFile := cfg.Paths.Router
+	if changes.exists(routerFile) {
+		importLine := pageRouterImport(feature, pageName)
+		imports := editDirectives(routerFile, func(d dartDirective) bool {
+			return sameDirective(d, importLine)
+		})
+		routes := 0
+		if shared == "" {
+			// a route with nested routes goes with all of them
+			routes = editGoRoutes(routerFile, func(r goRoute) bool {
+				return r.Class == class || r.Path == constName
+			})
+		}
+		page := pascalCase(pageName) + "Page"
+		switch {
+		case imports > 0 && routes > 0:
+			fmt.Printf("➖ Removed import and route for %s from %s\n", page, filepath.Base(routerFile))
+		case imports > 0:
+			fmt.Printf("➖ Removed import for %s from %s\n", page, filepath.Base(routerFile))
+		case routes > 0:
+			fmt.Printf("➖ Removed route for %s from %s\n", page, filepath.Base(routerFile))
+		}
+	}
+
+	if shared != "" {
+		fmt.Printf("⚠️  Kept route and %s: feature %s still has a %s page\n", constName, shared, pageName)
+		return
+	}
+	if changes.exists(cfg.Paths.PageNames) {
+		removed := editLines(cfg.Paths.PageNames, func(trimmed string) bool {
+			return strings.HasPrefix(trimmed, "const "+constName+" ")
+		})
+		if removed > 0 {
+			fmt.Printf("➖ Removed constant %s from %s\n", constName, filepath.Base(cfg.Paths.PageNames))
+		}
+	}
+}
+
+// editLines drops every line of path for which drop returns true (given the
+// trimmed line) and stages the result. It returns the number of lines dropped.
+func editLines(path string, drop func(trimmed string) bool) int {
+	data, err := changes.readFile(path)
+	if err != nil {
+		failf("Failed to read %s: %v", path, err)
+		return 0
+	}
+	lines := strings.Split(string(data), "\n")
+	kept := make([]string, 0, len(lines))
+	for _, line := range lines {
+		if drop(strings.TrimSpace(line)) {
+			continue
+		}
+		kept = append(kept, line)
+	}
+	removed := len(lines) - len(kept)
+	if removed == 0 {
+		return 0
+	}
+	if err := changes.writeFile(path, []byte(strings.Join(kept, "\n"))); err != nil {
+		failf("Failed to update %s: %v", path, err)
+		return 0
+	}
+	return removed
+}
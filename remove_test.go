@@ -0,0 +1,74 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRemoveFeatureDeletesTreesAndRegistrations(t *testing.T) {
+	withTempDir(t)
+	_ = runMain(t, "new", "feature", "orders")
+	_ = runMain(t, "new", "page", "orders", "details")
+	_ = runMain(t, "new", "feature", "cart")
+
+	out, code := runMainCode(t, "remove", "feature", "orders")
+	if code != 0 {
+		t.Fatalf("remove feature failed:\n%s", out)
+	}
+
+	for _, dir := range []string{filepath.Join("lib", "features", "orders"), filepath.Join("test", "features", "orders")} {
+		if _, err := os.Stat(dir); !os.IsNotExist(err) {
+			t.Fatalf("expected %s to be removed", dir)
+		}
+	}
+	mustExist(t, filepath.Join("lib", "features", "cart", "presentation", "pages", "cart_page.dart"))
+
+	router := mustReadFile(t, filepath.Join("lib", "core", "router.dart"))
+	if strings.Contains(router, "features/orders/") || strings.Contains(router, "kOrdersPage") || strings.Contains(router, "kDetailsPage") {
+		t.Fatalf("orders imports and routes should be stripped, got:\n%s", router)
+	}
+	if !strings.Contains(router, "GoRoute(path: kCartPage,") || !strings.Contains(router, "// AUTO_ROUTES") {
+		t.Fatalf("other routes and markers should be kept, got:\n%s", router)
+	}
+
+	names := mustReadFile(t, filepath.Join("lib", "core", "page_names.dart"))
+	if strings.Contains(names, "kOrdersPage") || strings.Contains(names, "kDetailsPage") || !strings.Contains(names, "kCartPage") {
+		t.Fatalf("unexpected page_names.dart after removal:\n%s", names)
+	}
+}
+
+func TestRemoveRefusesEditedFilesWithoutForce(t *testing.T) {
+	withTempDir(t)
+	_ = runMain(t, "new", "page", "orders", "details")
+
+	pagePath := filepath.Join("lib", "features", "orders", "presentation", "pages", "details_page.dart")
+	edited := mustReadFile(t, pagePath) + "// hand edit\n"
+	if err := os.WriteFile(pagePath, []byte(edited), 0644); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+
+	out, code := runMainCode(t, "remove", "page", "orders", "details")
+	if code == 0 || !strings.Contains(out, "Refusing to delete") {
+		t.Fatalf("expected refusal, got code %d:\n%s", code, out)
+	}
+	mustExist(t, pagePath)
+	if !strings.Contains(mustReadFile(t, filepath.Join("lib", "core", "router.dart")), "kDetailsPage") {
+		t.Fatalf("router.dart must be untouched after refusal")
+	}
+
+	out, code = runMainCode(t, "remove", "page", "orders", "details", "--force")
+	if code != 0 {
+		t.Fatalf("forced removal failed:\n%s", out)
+	}
+	if _, err := os.Stat(pagePath); !os.IsNotExist(err) {
+		t.Fatalf("expected page to be removed with --force")
+	}
+	if _, err := os.Stat(filepath.Join("test", "features", "orders", "presentation", "pages", "details_page_test.dart")); !os.IsNotExist(err) {
+		t.Fatalf("expected page test to be removed")
+	}
+	if strings.Contains(mustReadFile(t, filepath.Join("lib", "core", "page_names.dart")), "kDetailsPage") {
+		t.Fatalf("expected constant to be removed")
+	}
+}
@@ -0,0 +1,475 @@
+// rename.go
+package main
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// renameFeature moves a feature's lib/ and test/ trees, renames files and
+// identifiers derived from the feature name, and fixes every import, route and
+// page constant that points at them.
+func renameFeature(oldName, newName string) {
+	if oldName == newName {
+		failf("Old and new feature names are the same")
+		return
+	}
+	moves := map[string]string{}
+	var oldDirs []string
+	for _, root := range []string{"lib", "test"} {
+		oldRoot := filepath.Join(root, "features", oldName)
+		newRoot := filepath.Join(root, "features", newName)
+		files, dirs, err := collectTree(oldRoot)
+		if err != nil {
+			failf("Failed to read %s: %v", oldRoot, err)
+			return
+		}
+		if len(files) > 0 || len(dirs) > 0 {
+			if changes.exists(newRoot) {
+				failf("Cannot rename: %s already exists", newRoot)
+				return
+			}
+		}
+		for _, file := range files {
+			rel, _ := filepath.Rel(oldRoot, file)
+			dir, base := filepath.Split(rel)
+			moves[file] = filepath.Join(newRoot, dir, renameToken(base, snakeCase(oldName), snakeCase(newName)))
+		}
+		for _, dir := range dirs {
+			rel, _ := filepath.Rel(oldRoot, dir)
+			changes.mkdirAll(filepath.Join(newRoot, rel))
+		}
+		oldDirs = append(oldDirs, dirs...)
+	}
+	if len(moves) == 0 {
+		failf("Feature %s not found under lib/features or test/features", oldName)
+		return
+	}
+
+	pages := featurePages(oldName)
+	rewrite := func(content string) string {
+		return renameIdentifiers(content, oldName, newName)
+	}
+	if !moveFiles(moves, rewrite) {
+		return
+	}
+	for i := len(oldDirs) - 1; i >= 0; i-- {
+		changes.removeDir(oldDirs[i])
+	}
+
+	if cfg.RouteNames == routeNamesFeature {
+		renameRouteAlias(oldName, newName)
+	}
+	for _, page := range pages {
+		renamed := renameToken(page, snakeCase(oldName), snakeCase(newName))
+		if renamed != page || cfg.RouteNames == routeNamesFeature {
+			renamePageRegistration(oldName, page, newName, renamed)
+		}
+	}
+	renameFeatureRegistrations(oldName, newName)
+	renameShell(oldName, newName)
+	fmt.Printf("🎯 Renamed feature %s -> %s\n", oldName, newName)
+}
+
+// renamePage moves a page and its test, renames the page class and updates its
+// route constant, route path and every import of and reference to it.
+func renamePage(feature, oldName, newName string) {
+	if oldName == newName {
+		failf("Old and new page names are the same")
+		return
+	}
+	oldFile := pageFilePath(feature, oldName)
+	newFile := pageFilePath(feature, newName)
+	if !changes.exists(oldFile) {
+		failf("Page %s not found", oldFile)
+		return
+	}
+	if changes.exists(newFile) {
+		failf("Cannot rename: %s already exists", newFile)
+		return
+	}
+
+	moves := map[string]string{oldFile: newFile}
+	testDir := filepath.Join("test", "features", feature, "presentation", "pages")
+	oldTest := filepath.Join(testDir, oldName+"_page_test.dart")
+	if changes.exists(oldTest) {
+		moves[oldTest] = filepath.Join(testDir, newName+"_page_test.dart")
+	}
+
+	oldPath, _ := pageConstValue(pageConstName(feature, oldName))
+	rewrite := func(content string) string {
+		return renamePageIdentifiers(content, oldName, newName)
+	}
+	if !moveFiles(moves, rewrite) {
+		return
+	}
+	if !renamePageReferences(feature, oldName, newName, oldPath) {
+		return
+	}
+	renamePageRegistration(feature, oldName, feature, newName)
+	fmt.Printf("🎯 Renamed page %s -> %s\n", oldName, newName)
+}
+
+// renamePageReferences renames the page class in the Dart files of lib/ and
+// test/ that import the page, directly or through a file exporting it, and
+// its route constant and path literal wherever they appear. router.dart and
+// page_names.dart are left to renamePageRegistration.
+func renamePageReferences(feature, oldName, newName, oldPath string) bool {
+	oldClass, newClass := pascalCase(oldName)+"Page", pascalCase(newName)+"Page"
+	oldConst, newConst := pageConstName(feature, oldName), pageConstName(feature, newName)
+	newPath := renameRouteSegments(oldPath, feature, oldName, feature, newName)
+	pageFile := pageFilePath(feature, newName)
+	pkg := packageName()
+
+	// exports caches whether a file exports the page
+	exports := map[string]bool{}
+	reachesPage := func(file string, content string) bool {
+		for _, d := range parseDirectives(content) {
+			if d.Keyword != dirImport {
+				continue
+			}
+			target := resolveDartImport(file, d.URI(), pkg)
+			if target == pageFile {
+				return true
+			}
+			exported, seen := exports[target]
+			if !seen && target != "" {
+				if data, err := changes.readFile(target); err == nil {
+					for _, e := range parseDirectives(string(data)) {
+						if e.Keyword == dirExport && resolveDartImport(target, e.URI(), pkg) == pageFile {
+							exported = true
+						}
+					}
+				}
+				exports[target] = exported
+			}
+			if exported {
+				return true
+			}
+		}
+		return false
+	}
+
+	for _, file := range projectDartFiles() {
+		if file == cfg.Paths.Router || file == cfg.Paths.PageNames || file == pageFile {
+			continue
+		}
+		data, err := changes.readFile(file)
+		if err != nil {
+			failf("Failed to read %s: %v", file, err)
+			return false
+		}
+		content := renameToken(string(data), oldConst, newConst)
+		if oldPath != "" {
+			content = strings.ReplaceAll(content, "'"+oldPath+"'", "'"+newPath+"'")
+		}
+		if reachesPage(file, content) {
+			content = renameToken(content, oldClass, newClass)
+		}
+		if content == string(data) {
+			continue
+		}
+		if err := changes.writeFile(file, []byte(content)); err != nil {
+			failf("Failed to update %s: %v", file, err)
+			return false
+		}
+		fmt.Printf("🔗 Renamed references to %s in %s\n", oldClass, file)
+	}
+	return true
+}
+
+// moveFiles stages the moves (old path -> new path), rewriting the content of
+// each moved file and repointing imports in every Dart file of the project.
+func moveFiles(moves map[string]string, rewrite func(string) string) bool {
+	m, err := loadManifest()
+	if err != nil {
+		failf("Failed to read %s: %v", manifestFile, err)
+		return false
+	}
+	pkg := packageName()
+
+	sources := make([]string, 0, len(moves))
+	for src := range moves {
+		sources = append(sources, src)
+	}
+	sort.Strings(sources)
+
+	for _, src := range sources {
+		dst := moves[src]
+		data, err := changes.readFile(src)
+		if err != nil {
+			failf("Failed to read %s: %v", src, err)
+			return false
+		}
+		pristine := m.pristine(src)
+		content := string(data)
+		if strings.HasSuffix(src, ".dart") {
+			content = repointImports(content, src, dst, moves, pkg)
+			content = rewrite(content)
+		}
+		if err := changes.writeFile(dst, []byte(content)); err != nil {
+			failf("Failed writing %s: %v", dst, err)
+			return false
+		}
+		changes.moved[filepath.Clean(dst)] = filepath.Clean(src)
+		if pristine {
+			changes.markGenerated(dst)
+		}
+		if err := changes.remove(src); err != nil {
+			failf("Failed to delete %s: %v", src, err)
+			return false
+		}
+		fmt.Printf("🚚 Moved %s -> %s\n", src, dst)
+	}
+
+	for _, file := range projectDartFiles() {
+		if _, moved := moves[file]; moved {
+			continue
+		}
+		data, err := changes.readFile(file)
+		if err != nil {
+			failf("Failed to read %s: %v", file, err)
+			return false
+		}
+		content := repointImports(string(data), file, file, moves, pkg)
+		if content == string(data) {
+			continue
+		}
+		if err := changes.writeFile(file, []byte(content)); err != nil {
+			failf("Failed to update %s: %v", file, err)
+			return false
+		}
+		fmt.Printf("🔗 Updated imports in %s\n", file)
+	}
+	return true
+}
+
+// repointImports rewrites the directives of a file that moves from oldPath to
+// newPath so that they still reach their targets after moves are applied.
+// Relative and package: URIs into lib/ are both handled, and the directives
+// of a file that changed are sorted again.
+func repointImports(content, oldPath, newPath string, moves map[string]string, pkg string) string {
+	updated := rewriteURIs(content, func(uri string) string {
+		var target string
+		isPackage := false
+		switch {
+		case pkg != "" && strings.HasPrefix(uri, "package:"+pkg+"/"):
+			target = filepath.Join("lib", filepath.FromSlash(strings.TrimPrefix(uri, "package:"+pkg+"/")))
+			isPackage = true
+		case strings.Contains(uri, ":"):
+			return uri
+		default:
+			target = filepath.Clean(filepath.Join(filepath.Dir(oldPath), filepath.FromSlash(uri)))
+		}
+
+		if moved, ok := moves[target]; ok {
+			target = moved
+		} else if oldPath == newPath {
+			return uri
+		}
+
+		if isPackage {
+			rel, err := filepath.Rel("lib", target)
+			if err != nil {
+				return uri
+			}
+			return "package:" + pkg + "/" + filepath.ToSlash(rel)
+		}
+		return dartRelImport(newPath, target)
+	})
+	if updated == content {
+		return content
+	}
+	return sortDirectives(updated)
+}
+
+// renamePageRegistration renames a page's route constant and path in
+// page_names.dart, moving the paths of routes nested under it along, and its
+// constant, class and relative path in router.dart.
+func renamePageRegistration(oldFeature, oldName, newFeature, newName string) {
+	oldConst, newConst := pageConstName(oldFeature, oldName), pageConstName(newFeature, newName)
+	oldOwner, newOwner := routeOwner(oldFeature, oldName), routeOwner(newFeature, newName)
+	oldPath, hasPath := pageConstValue(oldConst)
+	newPath := renameRouteSegments(oldPath, oldFeature, oldName, newFeature, newName)
+
+	for _, file := range []string{cfg.Paths.PageNames, cfg.Paths.Router} {
+		if !changes.exists(file) {
+			continue
+		}
+		data, err := changes.readFile(file)
+		if err != nil {
+			failf("Failed to read %s: %v", file, err)
+			return
+		}
+		content := renamePageIdentifiers(string(data), oldName, newName)
+		content = renameToken(content, oldOwner, newOwner)
+		if file == cfg.Paths.PageNames && hasPath {
+			content = pageConstRe.ReplaceAllStringFunc(content, func(m string) string {
+				sub := pageConstRe.FindStringSubmatch(m)
+				if sub[2] != oldPath && !strings.HasPrefix(sub[2], oldPath+"/") {
+					return m
+				}
+				return fmt.Sprintf("const %s = '%s';", sub[1], newPath+strings.TrimPrefix(sub[2], oldPath))
+			})
+		}
+		if file == cfg.Paths.Router {
+			content = renameNestedRoutePath(content, pageRouteClass(newFeature, newName), oldName, newName)
+		}
+		if content == string(data) {
+			continue
+		}
+		if err := changes.writeFile(file, []byte(content)); err != nil {
+			failf("Failed to update %s: %v", file, err)
+			return
+		}
+		fmt.Printf("🔗 Renamed %s to %s in %s\n", oldConst, newConst, filepath.Base(file))
+	}
+}
+
+// renameRouteSegments renames the page's segment of a route path and, when
+// route names carry the feature, its leading feature segment.
+func renameRouteSegments(path, oldFeature, oldName, newFeature, newName string) string {
+	segments := strings.Split(path, "/")
+	for i := len(segments) - 1; i >= 0; i-- {
+		if segments[i] == oldName {
+			segments[i] = newName
+			break
+		}
+	}
+	if cfg.RouteNames == routeNamesFeature && len(segments) > 1 && segments[1] == oldFeature {
+		segments[1] = newFeature
+	}
+	return strings.Join(segments, "/")
+}
+
+// renameNestedRoutePath renames the page's segment in the relative path
+// literal of its nested GoRoute. Top-level routes use the constant instead.
+func renameNestedRoutePath(content, class, oldName, newName string) string {
+	routes := parseGoRoutes(content)
+	for i := len(routes) - 1; i >= 0; i-- {
+		r := routes[i]
+		if r.Class != class || !strings.HasPrefix(r.Path, "'") || !strings.HasSuffix(r.Path, "'") || len(r.Path) < 2 {
+			continue
+		}
+		path := renameRouteSegments(r.Path[1:len(r.Path)-1], "", oldName, "", newName)
+		content = content[:r.PathStart] + "'" + path + "'" + content[r.PathEnd:]
+	}
+	return content
+}
+
+// renameIdentifiers replaces the PascalCase, camelCase and snake_case forms of
+// oldName with those of newName.
+func renameIdentifiers(content, oldName, newName string) string {
+	content = renameToken(content, pascalCase(oldName), pascalCase(newName))
+	content = renameToken(content, camelCase(oldName), camelCase(newName))
+	content = renameToken(content, snakeCase(oldName), snakeCase(newName))
+	return content
+}
+
+// renamePageIdentifiers replaces the page class, its route constant, its route
+// path and its title.
+func renamePageIdentifiers(content, oldName, newName string) string {
+	content = renameToken(content, pascalCase(oldName)+"Page", pascalCase(newName)+"Page")
+	content = strings.ReplaceAll(content, "'/"+oldName+"'", "'/"+newName+"'")
+	content = strings.ReplaceAll(content, "Text('"+pascalCase(oldName)+"')", "Text('"+pascalCase(newName)+"')")
+	return content
+}
+
+// renameToken replaces occurrences of from that start a word and end at a
+// word boundary, an uppercase letter (PascalCase continuation) or an
+// underscore (snake_case continuation). A "k" constant prefix is allowed in
+// front of PascalCase names, so kOrdersPage follows OrdersPage.
+func renameToken(content, from, to string) string {
+	if from == "" || from == to {
+		return content
+	}
+	var b strings.Builder
+	for i := 0; i < len(content); {
+		j := strings.Index(content[i:], from)
+		if j == -1 {
+			b.WriteString(content[i:])
+			break
+		}
+		start := i + j
+		end := start + len(from)
+		if tokenStart(content, start, from) && tokenEnd(content, end) {
+			b.WriteString(content[i:start])
+			b.WriteString(to)
+		} else {
+			b.WriteString(content[i:end])
+		}
+		i = end
+	}
+	return b.String()
+}
+
+func tokenStart(content string, start int, from string) bool {
+	if start == 0 || !isIdentChar(content[start-1]) {
+		return true
+	}
+	isPascal := from[0] >= 'A' && from[0] <= 'Z'
+	return isPascal && content[start-1] == 'k' && (start == 1 || !isIdentChar(content[start-2]))
+}
+
+func tokenEnd(content string, end int) bool {
+	if end == len(content) {
+		return true
+	}
+	c := content[end]
+	return !isIdentChar(c) || c == '_' || (c >= 'A' && c <= 'Z')
+}
+
+func isIdentChar(c byte) bool {
+	return c == '_' || c == '$' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
+
+// projectDartFiles lists the Dart files under lib/ and test/, including files
+// staged by the running command.
+func projectDartFiles() []string {
+	seen := map[string]bool{}
+	var files []string
+	for _, root := range []string{"lib", "test"} {
+		_ = filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() || !strings.HasSuffix(p, ".dart") {
+				return nil
+			}
+			if changes.exists(p) && !seen[p] {
+				seen[p] = true
+				files = append(files, p)
+			}
+			return nil
+		})
+	}
+	for _, p := range changes.order {
+		if f := changes.files[p]; !f.deleted && strings.HasSuffix(p, ".dart") && !seen[p] {
+			root := strings.Split(filepath.ToSlash(p), "/")[0]
+			if root == "lib" || root == "test" {
+				seen[p] = true
+				files = append(files, p)
+			}
+		}
+	}
+	return files
+}
+
+// packageName returns the Dart package name from pubspec.yaml, or "" when it
+// cannot be read.
+func packageName() string {
+	data, err := os.ReadFile("pubspec.yaml")
+	if err != nil {
+		return ""
+	}
+	var pubspec struct {
+		Name string `yaml:"name"`
+	}
+	if err := yaml.Unmarshal(data, &pubspec); err != nil {
+		return ""
+	}
+	return pubspec.Name
+}
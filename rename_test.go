@@ -0,0 +1,163 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRenameToken(t *testing.T) {
+	tests := []struct {
+		in, from, to, expect string
+	}{
+		{in: "class OrdersPage {}", from: "Orders", to: "Purchases", expect: "class PurchasesPage {}"},
+		{in: "path: kOrdersPage,", from: "Orders", to: "Purchases", expect: "path: kPurchasesPage,"},
+		{in: "class FetchOrders {}", from: "Orders", to: "Purchases", expect: "class FetchOrders {}"},
+		{in: "ref.watch(ordersProvider)", from: "orders", to: "purchases", expect: "ref.watch(purchasesProvider)"},
+		{in: "part 'orders_provider.g.dart';", from: "orders", to: "purchases", expect: "part 'purchases_provider.g.dart';"},
+		{in: "final ordersCount = reorders;", from: "orders", to: "purchases", expect: "final purchasesCount = reorders;"},
+	}
+	for _, tt := range tests {
+		if got := renameToken(tt.in, tt.from, tt.to); got != tt.expect {
+			t.Fatalf("renameToken(%q): expected %q, got %q", tt.in, tt.expect, got)
+		}
+	}
+}
+
+func TestRenameFeatureMovesFilesAndFixesReferences(t *testing.T) {
+	withTempDir(t)
+	if err := os.WriteFile("pubspec.yaml", []byte("name: shop\n"), 0644); err != nil {
+		t.Fatalf("write pubspec failed: %v", err)
+	}
+	_ = runMain(t, "new", "feature", "orders")
+	_ = runMain(t, "new", "feature", "cart")
+
+	cartPage := filepath.Join("lib", "features", "cart", "presentation", "pages", "cart_page.dart")
+	extra := "import 'package:shop/features/orders/domain/entities/orders.dart';\n"
+	if err := os.WriteFile(cartPage, []byte(extra+mustReadFile(t, cartPage)), 0644); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+
+	out, code := runMainCode(t, "rename", "feature", "orders", "purchases")
+	if code != 0 {
+		t.Fatalf("rename failed:\n%s", out)
+	}
+
+	if _, err := os.Stat(filepath.Join("lib", "features", "orders")); !os.IsNotExist(err) {
+		t.Fatalf("old feature folder should be gone")
+	}
+	mustExist(t, filepath.Join("lib", "features", "purchases", "data", "models"))
+	mustExist(t, filepath.Join("test", "features", "purchases", "domain", "entities", "purchases_entity_test.dart"))
+
+	page := mustReadFile(t, filepath.Join("lib", "features", "purchases", "presentation", "pages", "purchases_page.dart"))
+	for _, expect := range []string{"import '../providers/purchases_provider.dart';", "class PurchasesPage", "ref.watch(purchasesProvider);"} {
+		if !strings.Contains(page, expect) {
+			t.Fatalf("expected page to contain %q, got:\n%s", expect, page)
+		}
+	}
+
+	router := mustReadFile(t, filepath.Join("lib", "core", "router.dart"))
+	if !strings.Contains(router, "import '../features/purchases/presentation/pages/purchases_page.dart';") ||
+		!strings.Contains(router, "GoRoute(path: kPurchasesPage, builder: (context, state) => const PurchasesPage()),") {
+		t.Fatalf("router not updated, got:\n%s", router)
+	}
+	names := mustReadFile(t, filepath.Join("lib", "core", "page_names.dart"))
+	if !strings.Contains(names, "const kPurchasesPage = '/purchases';") {
+		t.Fatalf("page constant not updated, got:\n%s", names)
+	}
+	if !strings.Contains(mustReadFile(t, cartPage), "import 'package:shop/features/purchases/domain/entities/purchases.dart';") {
+		t.Fatalf("package import in other feature not updated")
+	}
+
+	// renamed files stay tracked, so they can still be removed without --force
+	if out, code := runMainCode(t, "remove", "feature", "purchases"); code != 0 {
+		t.Fatalf("remove after rename failed:\n%s", out)
+	}
+}
+
+func TestRenamePageUpdatesRouteAndConstant(t *testing.T) {
+	withTempDir(t)
+	_ = runMain(t, "new", "page", "orders", "details")
+
+	out, code := runMainCode(t, "rename", "page", "orders", "details", "summary")
+	if code != 0 {
+		t.Fatalf("rename page failed:\n%s", out)
+	}
+
+	page := mustReadFile(t, filepath.Join("lib", "features", "orders", "presentation", "pages", "summary_page.dart"))
+	if !strings.Contains(page, "class SummaryPage extends ConsumerWidget") || !strings.Contains(page, "const SummaryPage({super.key});") {
+		t.Fatalf("page class not renamed, got:\n%s", page)
+	}
+	mustExist(t, filepath.Join("test", "features", "orders", "presentation", "pages", "summary_page_test.dart"))
+
+	router := mustReadFile(t, filepath.Join("lib", "core", "router.dart"))
+	if strings.Contains(router, "details") || !strings.Contains(router, "GoRoute(path: kSummaryPage, builder: (context, state) => const SummaryPage()),") {
+		t.Fatalf("router not updated, got:\n%s", router)
+	}
+	if !strings.Contains(mustReadFile(t, filepath.Join("lib", "core", "page_names.dart")), "const kSummaryPage = '/summary';") {
+		t.Fatalf("page constant not updated")
+	}
+}
+
+func TestRenamePageUpdatesReferences(t *testing.T) {
+	withTempDir(t)
+	_ = runMain(t, "new", "page", "orders", "detail")
+	_ = runMain(t, "new", "page", "cart", "cart")
+
+	link := filepath.Join("lib", "features", "orders", "presentation", "widgets", "detail_link.dart")
+	mustWriteFile(t, link, `import 'package:flutter/material.dart';
+import 'package:go_router/go_router.dart';
+
+import '../../../../core/page_names.dart';
+import '../pages/detail_page.dart';
+
+class DetailLink extends StatelessWidget {
+  const DetailLink({super.key});
+
+  @override
+  Widget build(BuildContext context) => TextButton(
+        onPressed: () => context.go(kDetailPage),
+        child: const Text('${DetailPage}'),
+      );
+}
+`)
+	barrel := filepath.Join("lib", "features", "orders", "orders.dart")
+	mustWriteFile(t, barrel, "export 'presentation/pages/detail_page.dart';\n")
+	checkout := filepath.Join("lib", "features", "cart", "presentation", "widgets", "checkout_button.dart")
+	mustWriteFile(t, checkout, `import 'package:go_router/go_router.dart';
+import 'package:shop/features/orders/orders.dart';
+
+void openOrder(GoRouter router) {
+  router.go('/detail');
+  DetailPage;
+}
+`)
+	unrelated := filepath.Join("lib", "features", "cart", "presentation", "widgets", "detail_page_hint.dart")
+	mustWriteFile(t, unrelated, "class DetailPage {}\n")
+	flow := filepath.Join("test", "features", "orders", "detail_flow_test.dart")
+	mustWriteFile(t, flow, `import 'package:shop/features/orders/presentation/pages/detail_page.dart';
+
+void main() {
+  const page = DetailPage();
+}
+`)
+
+	if out, code := runMainCode(t, "rename", "page", "orders", "detail", "receipt"); code != 0 {
+		t.Fatalf("rename page failed:\n%s", out)
+	}
+
+	for _, tt := range []struct{ path, expect string }{
+		{link, "import '../pages/receipt_page.dart';"},
+		{link, "context.go(kReceiptPage)"},
+		{link, "'${ReceiptPage}'"},
+		{checkout, "router.go('/receipt');\n  ReceiptPage;"},
+		{flow, "import 'package:shop/features/orders/presentation/pages/receipt_page.dart';"},
+		{flow, "const page = ReceiptPage();"},
+		{unrelated, "class DetailPage {}"},
+	} {
+		if got := mustReadFile(t, tt.path); !strings.Contains(got, tt.expect) {
+			t.Fatalf("expected %q in %s, got:\n%s", tt.expect, tt.path, got)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+// route_params.go
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// routeParam is a typed page argument read from the route's path or query.
+type routeParam struct {
+	dartField
+	Query bool
+}
+
+// routeParamParsers maps the supported parameter types to their parse and
+// tryParse expressions; %s is the raw string.
+var routeParamParsers = map[string][2]string{
+	"String":   {"%s", "%s"},
+	"int":      {"int.parse(%s)", "int.tryParse(%s)"},
+	"double":   {"double.parse(%s)", "double.tryParse(%s)"},
+	"num":      {"num.parse(%s)", "num.tryParse(%s)"},
+	"bool":     {"bool.parse(%s)", "bool.tryParse(%s)"},
+	"DateTime": {"DateTime.parse(%s)", "DateTime.tryParse(%s)"},
+}
+
+// parseRouteParams parses the --path-param and --query-param specs. Path
+// parameters are always present, so they cannot be nullable. Links may leave
+// a query parameter out, so its type is made nullable ("tab:String" gives a
+// String? field).
+func parseRouteParams(pathSpecs, querySpecs []string) ([]routeParam, error) {
+	pathFields, err := parseFieldSpecs(pathSpecs)
+	if err != nil {
+		return nil, err
+	}
+	queryFields, err := parseFieldSpecs(querySpecs)
+	if err != nil {
+		return nil, err
+	}
+
+	var params []routeParam
+	seen := map[string]bool{}
+	for i, f := range append(pathFields, queryFields...) {
+		p := routeParam{dartField: f, Query: i >= len(pathFields)}
+		if _, ok := routeParamParsers[p.BaseType()]; !ok {
+			return nil, fmt.Errorf("unsupported route param type %s for %s (use String, int, double, num, bool or DateTime)", p.Type, p.Name)
+		}
+		if !p.Query && p.Nullable() {
+			return nil, fmt.Errorf("path param %s cannot be nullable", p.Name)
+		}
+		if p.Query && !p.Nullable() {
+			p.Type = p.NullableType()
+		}
+		if seen[p.Name] {
+			return nil, fmt.Errorf("duplicate route param %q", p.Name)
+		}
+		seen[p.Name] = true
+		params = append(params, p)
+	}
+	return params, nil
+}
+
+// routePath is the page's route path, with a ":name" segment per path param.
+func routePath(pageName string, params []routeParam) string {
+	path := "/" + pageName
+	for _, p := range params {
+		if !p.Query {
+			path += "/:" + p.Name
+		}
+	}
+	return path
+}
+
+// ParseExpr reads the parameter from the GoRouterState named state. Required
+// (path) values are asserted present; nullable ones use tryParse, so a missing
+// or malformed value becomes null.
+func (p routeParam) ParseExpr(state string) string {
+	raw := fmt.Sprintf("%s.pathParameters['%s']", state, p.Name)
+	if p.Query {
+		raw = fmt.Sprintf("%s.uri.queryParameters['%s']", state, p.Name)
+	}
+	parsers := routeParamParsers[p.BaseType()]
+	switch {
+	case !p.Nullable():
+		return fmt.Sprintf(parsers[0], raw+"!")
+	case p.BaseType() == "String":
+		return raw
+	default:
+		return fmt.Sprintf(parsers[1], raw+" ?? ''")
+	}
+}
+
+// routeBuilder is the constructor call of the page class in a GoRoute builder.
+func routeBuilder(class string, params []routeParam) string {
+	if len(params) == 0 {
+		return "const " + class + "()"
+	}
+	args := make([]string, len(params))
+	for i, p := range params {
+		args[i] = p.Name + ": " + p.ParseExpr("state")
+	}
+	return class + "(" + strings.Join(args, ", ") + ")"
+}
+
+// pageFields are the constructor fields of a page taking params.
+func pageFields(params []routeParam) []dartField {
+	fields := make([]dartField, len(params))
+	for i, p := range params {
+		fields[i] = p.dartField
+	}
+	return fields
+}
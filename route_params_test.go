@@ -0,0 +1,55 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestNewPageWithRouteParams(t *testing.T) {
+	withTempDir(t)
+	_ = runMain(t, "new", "page", "orders", "detail", "--path-param", "id:int", "--query-param", "tab:String", "--query-param", "from:DateTime?")
+
+	page := mustReadFile(t, filepath.Join("lib", "features", "orders", "presentation", "pages", "detail_page.dart"))
+	for _, expect := range []string{
+		"class DetailPage extends ConsumerWidget {\n  final int id;\n  final String? tab;\n  final DateTime? from;\n\n",
+		"  const DetailPage({super.key, required this.id, this.tab, this.from});",
+	} {
+		if !strings.Contains(page, expect) {
+			t.Fatalf("expected page to contain %q, got:\n%s", expect, page)
+		}
+	}
+
+	names := mustReadFile(t, filepath.Join("lib", "core", "page_names.dart"))
+	if !strings.Contains(names, "const kDetailPage = '/detail/:id';") {
+		t.Fatalf("expected path constant with the id segment, got:\n%s", names)
+	}
+	router := mustReadFile(t, filepath.Join("lib", "core", "router.dart"))
+	expect := "GoRoute(path: kDetailPage, builder: (context, state) => DetailPage(id: int.parse(state.pathParameters['id']!), tab: state.uri.queryParameters['tab'], from: DateTime.tryParse(state.uri.queryParameters['from'] ?? ''))),"
+	if !strings.Contains(router, expect) {
+		t.Fatalf("expected typed route builder, got:\n%s", router)
+	}
+
+	_ = runMain(t, "remove", "page", "orders", "detail", "--force")
+	router = mustReadFile(t, filepath.Join("lib", "core", "router.dart"))
+	if strings.Contains(router, "DetailPage") {
+		t.Fatalf("expected the route to be removed, got:\n%s", router)
+	}
+}
+
+func TestParseRouteParamsRejectsInvalidParams(t *testing.T) {
+	tests := []struct {
+		path, query []string
+		expect      string
+	}{
+		{path: []string{"id:int?"}, expect: "path param id cannot be nullable"},
+		{query: []string{"ids:List<int>"}, expect: "unsupported route param type List<int>"},
+		{path: []string{"id:int"}, query: []string{"id:String?"}, expect: "duplicate route param \"id\""},
+	}
+	for _, tt := range tests {
+		_, err := parseRouteParams(tt.path, tt.query)
+		if err == nil || !strings.Contains(err.Error(), tt.expect) {
+			t.Fatalf("expected error containing %q, got %v", tt.expect, err)
+		}
+	}
+}
@@ -0,0 +1,529 @@
+// routes.go
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Route naming strategies selected by the route_names config key. With
+// "feature", page constants and paths are prefixed with the feature name and
+// router.dart imports pages under the feature as prefix, so two features can
+// have a page of the same name.
+const (
+	routeNamesPage    = "page"
+	routeNamesFeature = "feature"
+)
+
+// namespacedRoute reports whether the page's constant and path carry the
+// feature name. A page named after its feature is not prefixed twice.
+func namespacedRoute(feature, pageName string) bool {
+	return cfg.RouteNames == routeNamesFeature && pageName != feature
+}
+
+// pageConstName returns the page_names.dart constant used as the page's route path.
+func pageConstName(feature, pageName string) string {
+	return "k" + routeOwner(feature, pageName)
+}
+
+// routeOwner names the page's route in markers, e.g. "DetailPage" or, with
+// feature route names, "OrdersDetailPage".
+func routeOwner(feature, pageName string) string {
+	if namespacedRoute(feature, pageName) {
+		return pascalCase(feature) + pascalCase(pageName) + "Page"
+	}
+	return pascalCase(pageName) + "Page"
+}
+
+// pageRoutePath is the path of a top-level page route.
+func pageRoutePath(feature, pageName string, params []routeParam) string {
+	if namespacedRoute(feature, pageName) {
+		return "/" + feature + routePath(pageName, params)
+	}
+	return routePath(pageName, params)
+}
+
+// pageRouteClass is the page class as router.dart refers to it.
+func pageRouteClass(feature, pageName string) string {
+	if cfg.RouteNames == routeNamesFeature {
+		return feature + "." + pascalCase(pageName) + "Page"
+	}
+	return pascalCase(pageName) + "Page"
+}
+
+// pageRouterImport is the directive importing the page into router.dart.
+func pageRouterImport(feature, pageName string) string {
+	uri := dartRelImport(cfg.Paths.Router, pageFilePath(feature, pageName))
+	if cfg.RouteNames == routeNamesFeature {
+		return fmt.Sprintf("import '%s' as %s;", uri, feature)
+	}
+	return fmt.Sprintf("import '%s';", uri)
+}
+
+// routerMarker is the top-level marker of router.dart's routes list.
+const routerMarker = "// AUTO_ROUTES"
+
+// routesMarker marks the routes list owned by a page or shell class, so nested
+// lists stay machine-editable like the top-level one.
+func routesMarker(owner string) string {
+	return routerMarker + ":" + owner
+}
+
+// branchesMarker marks the branches list of a StatefulShellRoute.
+func branchesMarker(owner string) string {
+	return "// AUTO_BRANCHES:" + owner
+}
+
+const baseRouter = `import 'package:flutter/material.dart';
+import 'package:go_router/go_router.dart';
+import 'package:riverpod_annotation/riverpod_annotation.dart';
+part 'router.g.dart';
+
+// REQUIRED_FOR_FARCH: Do not remove this marker. farch inserts page imports above this line.
+// AUTO_IMPORTS
+
+@riverpod
+Raw<GoRouter> router(Ref ref) => GoRouter(
+  routes: [
+    // REQUIRED_FOR_FARCH: Do not remove this marker. farch inserts new GoRoute entries above this line.
+    // AUTO_ROUTES
+  ],
+);
+`
+
+// ensureRouter creates router.dart when it does not exist yet.
+func ensureRouter() bool {
+	routerFile := cfg.Paths.Router
+	changes.mkdirAll(filepath.Dir(routerFile))
+	if changes.exists(routerFile) {
+		return true
+	}
+	if err := changes.writeFile(routerFile, []byte(baseRouter)); err != nil {
+		failf("Failed to create %s: %v", routerFile, err)
+		return false
+	}
+	changes.createdf("📝 Created %s\n", routerFile)
+	return true
+}
+
+// markerLine returns the index of the line that is exactly marker, or -1.
+func markerLine(lines []string, marker string) int {
+	for i, line := range lines {
+		if strings.TrimSpace(line) == marker {
+			return i
+		}
+	}
+	return -1
+}
+
+// insertAboveMarker inserts text above the marker line, indenting each of its
+// lines like the marker.
+func insertAboveMarker(content, marker, text string) (string, bool) {
+	lines := strings.Split(content, "\n")
+	i := markerLine(lines, marker)
+	if i == -1 {
+		return content, false
+	}
+	indent := leadingSpace(lines[i])
+	var block []string
+	for _, l := range strings.Split(text, "\n") {
+		block = append(block, indent+l)
+	}
+	lines = append(lines[:i], append(block, lines[i:]...)...)
+	return strings.Join(lines, "\n"), true
+}
+
+func leadingSpace(line string) string {
+	return line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+}
+
+// bracketDelta returns how many parentheses and brackets a line opens minus
+// how many it closes, ignoring quoted text and comments.
+func bracketDelta(line string) int {
+	depth := 0
+	quote := byte(0)
+	for j := 0; j < len(line); j++ {
+		c := line[j]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == '/' && j+1 < len(line) && line[j+1] == '/':
+			return depth
+		case c == '(' || c == '[':
+			depth++
+		case c == ')' || c == ']':
+			depth--
+		}
+	}
+	return depth
+}
+
+// blockEnd returns the index of the line that closes the parentheses and
+// brackets opened on line start.
+func blockEnd(lines []string, start int) int {
+	depth := 0
+	for i := start; i < len(lines); i++ {
+		depth += bracketDelta(lines[i])
+		if depth <= 0 {
+			return i
+		}
+	}
+	return len(lines) - 1
+}
+
+// pageConstRe matches a page_names.dart constant and captures its name and path.
+var pageConstRe = regexp.MustCompile(`(?m)^const\s+(k\w+Page)\s*=\s*'([^']*)';`)
+
+// pageConstValue returns the path held by a page_names.dart constant.
+func pageConstValue(constName string) (string, bool) {
+	data, err := changes.readFile(cfg.Paths.PageNames)
+	if err != nil {
+		return "", false
+	}
+	for _, m := range pageConstRe.FindAllStringSubmatch(string(data), -1) {
+		if m[1] == constName {
+			return m[2], true
+		}
+	}
+	return "", false
+}
+
+// checkRouteCollision fails when the page's constant is taken by another
+// page or its path matches the same locations as another route. Running new
+// page again for an existing page is not a collision.
+func checkRouteCollision(feature, pageName, path string) bool {
+	data, err := changes.readFile(cfg.Paths.PageNames)
+	if err != nil {
+		return true
+	}
+	constName := pageConstName(feature, pageName)
+	for _, m := range pageConstRe.FindAllStringSubmatch(string(data), -1) {
+		name, value := m[1], m[2]
+		switch {
+		case name == constName && !changes.exists(pageFilePath(feature, pageName)):
+			owner := "another page"
+			if other := pageOwner(feature, pageName); other != "" {
+				owner = "feature " + other
+			}
+			hint := ""
+			if cfg.RouteNames == routeNamesPage {
+				hint = "; set route_names: feature to prefix route constants with the feature"
+			}
+			failf("%s in %s is already used by %s%s", constName, filepath.Base(cfg.Paths.PageNames), owner, hint)
+			return false
+		case name == constName && value != path:
+			failf("%s is already declared with path %s, not %s", constName, value, path)
+			return false
+		case name != constName && routePattern(value) == routePattern(path):
+			failf("Route path %s of %s conflicts with %s (%s)", path, constName, name, value)
+			return false
+		}
+	}
+	return true
+}
+
+// pageOwner returns another feature with a page of the same name, or "".
+func pageOwner(feature, pageName string) string {
+	entries, err := os.ReadDir(filepath.Join("lib", "features"))
+	if err != nil {
+		return ""
+	}
+	for _, e := range entries {
+		if e.IsDir() && e.Name() != feature && changes.exists(pageFilePath(e.Name(), pageName)) {
+			return e.Name()
+		}
+	}
+	return ""
+}
+
+// routePattern blanks the parameter names of a path, so paths matching the
+// same locations compare equal ("/detail/:id" and "/detail/:slug/").
+func routePattern(path string) string {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	for i, s := range segments {
+		if strings.HasPrefix(s, ":") {
+			segments[i] = ":"
+		}
+	}
+	return "/" + strings.Join(segments, "/")
+}
+
+// parentRoutePath checks that a parent page of the feature is routed and
+// returns its full path, which nested pages extend.
+func parentRoutePath(feature, parent string) (string, bool) {
+	routerFile := cfg.Paths.Router
+	data, err := changes.readFile(routerFile)
+	if err != nil || !hasPageRoute(string(data), feature, parent) {
+		failf("Parent page %s has no route in %s; create it first with: new page %s %s", parent, routerFile, feature, parent)
+		return "", false
+	}
+	constName := pageConstName(feature, parent)
+	path, ok := pageConstValue(constName)
+	if !ok {
+		failf("Parent page %s has no %s constant in %s", parent, constName, cfg.Paths.PageNames)
+		return "", false
+	}
+	return path, true
+}
+
+// nestRoute adds route as a child of the parent page's GoRoute, at the end of
+// its routes list, and marks the list so later routes go above the marker.
+// The list is opened on the GoRoute when it has none.
+func nestRoute(content, feature, parentPage, route string) (string, error) {
+	owner := routeOwner(feature, parentPage)
+	marker := routesMarker(owner)
+	if updated, ok := insertAboveMarker(content, marker, route); ok {
+		return updated, nil
+	}
+
+	routes := routesOfPage(content, feature, parentPage)
+	if len(routes) == 0 {
+		return content, fmt.Errorf("no GoRoute for %s in %s", owner, filepath.Base(cfg.Paths.Router))
+	}
+	r := routes[0]
+	if r.Routes != -1 {
+		return appendToList(content, r.RoutesEnd, []string{route, marker}), nil
+	}
+	// close is the parenthesis ending the GoRoute call
+	close := r.End - 1
+	if strings.TrimSpace(content[lineStart(content, close):close]) == "" {
+		return appendToList(content, close, []string{"routes: [", "  " + route, "  " + marker, "],"}), nil
+	}
+	indent := leadingSpace(content[lineStart(content, r.Start):])
+	sep := ", "
+	if strings.HasSuffix(strings.TrimRight(content[:close], " \t\r\n"), ",") {
+		sep = " "
+	}
+	nested := sep + "routes: [\n" + indent + "  " + route + "\n" + indent + "  " + marker + "\n" + indent + "]"
+	return content[:close] + nested + content[close:], nil
+}
+
+// appendToList inserts lines as the last entries of the argument or element
+// list closed by the bracket at offset close, one per line and indented one
+// step deeper than the line of the bracket. A comma is added after the entry
+// before them when it has none and does not end in a comment.
+func appendToList(content string, close int, lines []string) string {
+	head := strings.TrimRight(content[:close], " \t\r\n")
+	own := strings.TrimSpace(content[lineStart(content, close):close]) == ""
+	indent := leadingSpace(content[lineStart(content, close):])
+	var b strings.Builder
+	if !own {
+		b.WriteString("\n")
+	}
+	for _, l := range lines {
+		b.WriteString(indent + "  " + l + "\n")
+	}
+	at := close
+	if own {
+		at = lineStart(content, close)
+	} else {
+		b.WriteString(indent)
+	}
+	content = content[:at] + b.String() + content[at:]
+	last := head[lineStart(head, len(head)):]
+	if !strings.HasSuffix(head, ",") && !strings.HasSuffix(head, "[") && !strings.HasSuffix(head, "(") && !strings.Contains(last, "//") {
+		content = content[:len(head)] + "," + content[len(head):]
+	}
+	return content
+}
+
+// nestedRouteCount counts the GoRoutes nested under the page's route.
+func nestedRouteCount(content, feature, pageName string) int {
+	routes := routesOfPage(content, feature, pageName)
+	if len(routes) == 0 {
+		return 0
+	}
+	count := 0
+	for _, r := range parseGoRoutes(content) {
+		if r.Start > routes[0].Start && r.End <= routes[0].End {
+			count++
+		}
+	}
+	return count
+}
+
+// Shell kinds selected by new feature --shell.
+const (
+	shellPlain    = "shell"
+	shellStateful = "stateful"
+)
+
+func shellClass(feature string) string {
+	return pascalCase(feature) + "Shell"
+}
+
+func shellFilePath(feature string) string {
+	return filepath.Join("lib", "features", feature, "presentation", "widgets", feature+"_shell.dart")
+}
+
+// createShell writes the feature's shell widget and wraps a ShellRoute (or a
+// StatefulShellRoute with one branch per page) around the feature's routes.
+// Pages of the feature are then added inside it.
+func createShell(feature, kind string) {
+	file := shellFilePath(feature)
+	changes.mkdirAll(filepath.Dir(file))
+	writeTemplate(file, "shell.dart.tmpl", templateData{Feature: feature, Name: feature, Kind: kind})
+
+	if !ensureRouter() {
+		return
+	}
+	routerFile := cfg.Paths.Router
+	data, err := changes.readFile(routerFile)
+	if err != nil {
+		failf("Failed to read %s: %v", routerFile, err)
+		return
+	}
+	content := insertImportDirective(string(data), fmt.Sprintf("import '%s';", dartRelImport(routerFile, file)))
+
+	class := shellClass(feature)
+	if strings.Contains(content, routesMarker(class)) || strings.Contains(content, branchesMarker(class)) {
+		fmt.Printf("⚠️  %s already has a shell for %s (kept)\n", filepath.Base(routerFile), feature)
+	} else {
+		block := "ShellRoute(\n" +
+			"  builder: (context, state, child) => " + class + "(child: child),\n" +
+			"  routes: [\n" +
+			"    " + routesMarker(class) + "\n" +
+			"  ],\n" +
+			"),"
+		if kind == shellStateful {
+			block = "StatefulShellRoute.indexedStack(\n" +
+				"  builder: (context, state, navigationShell) => " + class + "(navigationShell: navigationShell),\n" +
+				"  branches: [\n" +
+				"    " + branchesMarker(class) + "\n" +
+				"  ],\n" +
+				"),"
+		}
+		updated, ok := insertAboveMarker(content, routerMarker, block)
+		if !ok {
+			failf("%s is missing the %s marker; cannot add the %s shell", filepath.Base(routerFile), routerMarker, feature)
+			return
+		}
+		content = updated
+		fmt.Printf("🐚 Added %s for %s to %s\n", strings.SplitN(block, "(", 2)[0], class, filepath.Base(routerFile))
+	}
+	if err := changes.writeFile(routerFile, []byte(content)); err != nil {
+		failf("Failed to update %s: %v", routerFile, err)
+	}
+}
+
+// addShellRoute adds route inside the feature's shell, if it has one.
+func addShellRoute(content, feature, route string) (string, bool) {
+	class := shellClass(feature)
+	if updated, ok := insertAboveMarker(content, routesMarker(class), route); ok {
+		return updated, true
+	}
+	return insertAboveMarker(content, branchesMarker(class), "StatefulShellBranch(routes: ["+strings.TrimSuffix(route, ",")+"]),")
+}
+
+// unregisterShell removes the feature's shell route and its import from
+// router.dart.
+func unregisterShell(feature string) {
+	routerFile := cfg.Paths.Router
+	if !changes.exists(routerFile) {
+		return
+	}
+	data, err := changes.readFile(routerFile)
+	if err != nil {
+		failf("Failed to read %s: %v", routerFile, err)
+		return
+	}
+	lines := strings.Split(string(data), "\n")
+	class := shellClass(feature)
+	m := markerLine(lines, routesMarker(class))
+	if m == -1 {
+		m = markerLine(lines, branchesMarker(class))
+	}
+	if m == -1 {
+		return
+	}
+	start := m
+	for start >= 0 {
+		trimmed := strings.TrimSpace(lines[start])
+		if strings.HasPrefix(trimmed, "ShellRoute(") || strings.HasPrefix(trimmed, "StatefulShellRoute.") {
+			break
+		}
+		start--
+	}
+	if start < 0 {
+		return
+	}
+	end := blockEnd(lines, start)
+	lines = append(lines[:start], lines[end+1:]...)
+
+	importLine := fmt.Sprintf("import '%s';", dartRelImport(routerFile, shellFilePath(feature)))
+	content, _ := removeDirectives(strings.Join(lines, "\n"), func(d dartDirective) bool {
+		return sameDirective(d, importLine)
+	})
+	if err := changes.writeFile(routerFile, []byte(content)); err != nil {
+		failf("Failed to update %s: %v", routerFile, err)
+		return
+	}
+	fmt.Printf("➖ Removed shell %s from %s\n", class, filepath.Base(routerFile))
+}
+
+// renameShell renames the feature's shell class and the markers of its route
+// lists in router.dart.
+func renameShell(oldName, newName string) {
+	routerFile := cfg.Paths.Router
+	if !changes.exists(routerFile) {
+		return
+	}
+	data, err := changes.readFile(routerFile)
+	if err != nil {
+		failf("Failed to read %s: %v", routerFile, err)
+		return
+	}
+	content := renameToken(string(data), shellClass(oldName), shellClass(newName))
+	if content == string(data) {
+		return
+	}
+	if err := changes.writeFile(routerFile, []byte(content)); err != nil {
+		failf("Failed to update %s: %v", routerFile, err)
+		return
+	}
+	fmt.Printf("🔗 Renamed %s to %s in %s\n", shellClass(oldName), shellClass(newName), filepath.Base(routerFile))
+}
+
+// renameRouteAlias renames the prefix router.dart imports the feature's pages
+// under when route names carry the feature.
+func renameRouteAlias(oldName, newName string) {
+	routerFile := cfg.Paths.Router
+	if !changes.exists(routerFile) {
+		return
+	}
+	data, err := changes.readFile(routerFile)
+	if err != nil {
+		failf("Failed to read %s: %v", routerFile, err)
+		return
+	}
+	content := string(data)
+	directives := parseDirectives(content)
+	alias := regexp.MustCompile(`\bas(\s+)` + regexp.QuoteMeta(oldName) + `\b`)
+	for i := len(directives) - 1; i >= 0; i-- {
+		d := directives[i]
+		if d.Keyword == dirImport && d.Prefix == oldName {
+			content = content[:d.Start] + alias.ReplaceAllString(content[d.Start:d.End], "as${1}"+newName) + content[d.End:]
+		}
+	}
+	inDirective := directiveLines(directives)
+	lines := strings.Split(content, "\n")
+	for i, line := range lines {
+		if !inDirective[i] {
+			lines[i] = renameToken(line, oldName+".", newName+".")
+		}
+	}
+	content = strings.Join(lines, "\n")
+	if content == string(data) {
+		return
+	}
+	if err := changes.writeFile(routerFile, []byte(content)); err != nil {
+		failf("Failed to update %s: %v", routerFile, err)
+	}
+}
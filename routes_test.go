@@ -0,0 +1,288 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestNewPageWithParentNestsRoute(t *testing.T) {
+	withTempDir(t)
+	_ = runMain(t, "new", "page", "orders", "orders")
+	_ = runMain(t, "new", "page", "orders", "detail", "--parent", "orders", "--path-param", "id:int")
+	_ = runMain(t, "new", "page", "orders", "invoice", "--parent", "detail")
+
+	names := mustReadFile(t, filepath.Join("lib", "core", "page_names.dart"))
+	for _, expect := range []string{
+		"const kOrdersPage = '/orders';",
+		"const kDetailPage = '/orders/detail/:id';",
+		"const kInvoicePage = '/orders/detail/:id/invoice';",
+	} {
+		if !strings.Contains(names, expect) {
+			t.Fatalf("expected %q, got:\n%s", expect, names)
+		}
+	}
+
+	router := mustReadFile(t, filepath.Join("lib", "core", "router.dart"))
+	expect := "    GoRoute(path: kOrdersPage, builder: (context, state) => const OrdersPage(), routes: [\n" +
+		"      GoRoute(path: 'detail/:id', builder: (context, state) => DetailPage(id: int.parse(state.pathParameters['id']!)), routes: [\n" +
+		"        GoRoute(path: 'invoice', builder: (context, state) => const InvoicePage()),\n" +
+		"        // AUTO_ROUTES:DetailPage\n" +
+		"      ]),\n" +
+		"      // AUTO_ROUTES:OrdersPage\n" +
+		"    ]),\n" +
+		"    // AUTO_ROUTES\n"
+	if !strings.Contains(router, expect) {
+		t.Fatalf("expected nested routes, got:\n%s", router)
+	}
+
+	out, code := runMainCode(t, "remove", "page", "orders", "detail", "--force")
+	if code == 0 || !strings.Contains(out, "has 1 nested route(s)") {
+		t.Fatalf("expected removing a parent page to fail, got code %d:\n%s", code, out)
+	}
+
+	_ = runMain(t, "rename", "page", "orders", "invoice", "receipt")
+	router = mustReadFile(t, filepath.Join("lib", "core", "router.dart"))
+	names = mustReadFile(t, filepath.Join("lib", "core", "page_names.dart"))
+	if !strings.Contains(router, "GoRoute(path: 'receipt', builder: (context, state) => const ReceiptPage())") ||
+		!strings.Contains(names, "const kReceiptPage = '/orders/detail/:id/receipt';") {
+		t.Fatalf("expected the nested path to be renamed, got:\n%s\n%s", router, names)
+	}
+
+	_ = runMain(t, "remove", "page", "orders", "receipt", "--force")
+	_ = runMain(t, "remove", "page", "orders", "detail", "--force")
+	router = mustReadFile(t, filepath.Join("lib", "core", "router.dart"))
+	if strings.Contains(router, "DetailPage") || strings.Contains(router, "ReceiptPage") || !strings.Contains(router, "const OrdersPage()") {
+		t.Fatalf("expected only the orders route to remain, got:\n%s", router)
+	}
+}
+
+func TestNewPageNestsUnderFormattedParent(t *testing.T) {
+	withTempDir(t)
+	_ = runMain(t, "new", "page", "orders", "orders")
+	_ = runMain(t, "new", "page", "cart", "cart")
+
+	routerPath := filepath.Join("lib", "core", "router.dart")
+	formatted := strings.NewReplacer(
+		"GoRoute(path: kOrdersPage, builder: (context, state) => const OrdersPage()),",
+		"GoRoute(\n      path: kOrdersPage,\n      builder: (context, state) => const OrdersPage(),\n    ),",
+		"GoRoute(path: kCartPage, builder: (context, state) => const CartPage()),",
+		"GoRoute(\n      path: kCartPage,\n      builder: (context, state) => const CartPage(),\n      routes: [\n        GoRoute(path: 'help', builder: (context, state) => const HelpPage())\n      ],\n    ),",
+	).Replace(mustReadFile(t, routerPath))
+	mustWriteFile(t, routerPath, formatted)
+
+	if out, code := runMainCode(t, "new", "page", "orders", "detail", "--parent", "orders"); code != 0 {
+		t.Fatalf("expected nesting under a formatted parent to work, got code %d:\n%s", code, out)
+	}
+	_ = runMain(t, "new", "page", "orders", "invoice", "--parent", "orders")
+	if out, code := runMainCode(t, "new", "page", "cart", "summary", "--parent", "cart"); code != 0 {
+		t.Fatalf("expected nesting into an existing routes list to work, got code %d:\n%s", code, out)
+	}
+
+	router := mustReadFile(t, routerPath)
+	for _, expect := range []string{
+		"    GoRoute(\n" +
+			"      path: kOrdersPage,\n" +
+			"      builder: (context, state) => const OrdersPage(),\n" +
+			"      routes: [\n" +
+			"        GoRoute(path: 'detail', builder: (context, state) => const DetailPage()),\n" +
+			"        GoRoute(path: 'invoice', builder: (context, state) => const InvoicePage()),\n" +
+			"        // AUTO_ROUTES:OrdersPage\n" +
+			"      ],\n" +
+			"    ),\n",
+		"      routes: [\n" +
+			"        GoRoute(path: 'help', builder: (context, state) => const HelpPage()),\n" +
+			"        GoRoute(path: 'summary', builder: (context, state) => const SummaryPage()),\n" +
+			"        // AUTO_ROUTES:CartPage\n" +
+			"      ],\n",
+	} {
+		if !strings.Contains(router, expect) {
+			t.Fatalf("expected %q in router.dart, got:\n%s", expect, router)
+		}
+	}
+}
+
+func TestNewPageWithUnknownParentFails(t *testing.T) {
+	withTempDir(t)
+	out, code := runMainCode(t, "new", "page", "orders", "detail", "--parent", "orders")
+	if code == 0 || !strings.Contains(out, "Parent page orders has no route") {
+		t.Fatalf("expected a missing parent to fail, got code %d:\n%s", code, out)
+	}
+	mustNotExist(t, filepath.Join("lib", "features", "orders", "presentation", "pages", "detail_page.dart"))
+}
+
+func TestNewFeatureWithShell(t *testing.T) {
+	withTempDir(t)
+	_ = runMain(t, "new", "feature", "orders", "--shell")
+
+	shell := mustReadFile(t, filepath.Join("lib", "features", "orders", "presentation", "widgets", "orders_shell.dart"))
+	if !strings.Contains(shell, "const OrdersShell({super.key, required this.child});") {
+		t.Fatalf("expected a shell widget taking a child, got:\n%s", shell)
+	}
+	router := mustReadFile(t, filepath.Join("lib", "core", "router.dart"))
+	expect := "    ShellRoute(\n" +
+		"      builder: (context, state, child) => OrdersShell(child: child),\n" +
+		"      routes: [\n" +
+		"        GoRoute(path: kOrdersPage, builder: (context, state) => const OrdersPage()),\n" +
+		"        // AUTO_ROUTES:OrdersShell\n" +
+		"      ],\n" +
+		"    ),\n"
+	if !strings.Contains(router, expect) || !strings.Contains(router, "import '../features/orders/presentation/widgets/orders_shell.dart';") {
+		t.Fatalf("expected the feature page inside a ShellRoute, got:\n%s", router)
+	}
+
+	_ = runMain(t, "rename", "feature", "orders", "purchases")
+	router = mustReadFile(t, filepath.Join("lib", "core", "router.dart"))
+	if !strings.Contains(router, "PurchasesShell(child: child)") || !strings.Contains(router, "// AUTO_ROUTES:PurchasesShell") {
+		t.Fatalf("expected the shell to be renamed, got:\n%s", router)
+	}
+
+	_ = runMain(t, "remove", "feature", "purchases", "--force")
+	router = mustReadFile(t, filepath.Join("lib", "core", "router.dart"))
+	if strings.Contains(router, "ShellRoute") || strings.Contains(router, "purchases_shell.dart") {
+		t.Fatalf("expected the shell to be removed, got:\n%s", router)
+	}
+}
+
+func TestNewFeatureWithStatefulShell(t *testing.T) {
+	withTempDir(t)
+	_ = runMain(t, "new", "feature", "home", "--shell=stateful")
+	_ = runMain(t, "new", "page", "home", "settings")
+
+	shell := mustReadFile(t, filepath.Join("lib", "features", "home", "presentation", "widgets", "home_shell.dart"))
+	if !strings.Contains(shell, "final StatefulNavigationShell navigationShell;") {
+		t.Fatalf("expected a shell widget taking the navigation shell, got:\n%s", shell)
+	}
+	router := mustReadFile(t, filepath.Join("lib", "core", "router.dart"))
+	for _, expect := range []string{
+		"    StatefulShellRoute.indexedStack(\n      builder: (context, state, navigationShell) => HomeShell(navigationShell: navigationShell),\n      branches: [\n",
+		"        StatefulShellBranch(routes: [GoRoute(path: kHomePage, builder: (context, state) => const HomePage())]),\n",
+		"        StatefulShellBranch(routes: [GoRoute(path: kSettingsPage, builder: (context, state) => const SettingsPage())]),\n        // AUTO_BRANCHES:HomeShell\n",
+	} {
+		if !strings.Contains(router, expect) {
+			t.Fatalf("expected %q, got:\n%s", expect, router)
+		}
+	}
+
+	_ = runMain(t, "remove", "page", "home", "settings", "--force")
+	router = mustReadFile(t, filepath.Join("lib", "core", "router.dart"))
+	if strings.Contains(router, "SettingsPage") || !strings.Contains(router, "// AUTO_BRANCHES:HomeShell") {
+		t.Fatalf("expected only the settings branch to be removed, got:\n%s", router)
+	}
+}
+
+func TestNewFeatureShellValueAfterSpace(t *testing.T) {
+	withTempDir(t)
+	_ = runMain(t, "new", "feature", "home", "--shell", "stateful")
+	_ = runMain(t, "new", "feature", "orders", "--shell", "shell")
+
+	router := mustReadFile(t, filepath.Join("lib", "core", "router.dart"))
+	if !strings.Contains(router, "StatefulShellRoute.indexedStack(") || !strings.Contains(router, "// AUTO_BRANCHES:HomeShell") {
+		t.Fatalf("expected a stateful shell for home, got:\n%s", router)
+	}
+	if !strings.Contains(router, "builder: (context, state, child) => OrdersShell(child: child),") {
+		t.Fatalf("expected a plain shell for orders, got:\n%s", router)
+	}
+}
+
+func TestNewFeatureRejectsUnknownShell(t *testing.T) {
+	withTempDir(t)
+	out, _ := runMainCode(t, "new", "feature", "orders", "--shell=tabs")
+	if !strings.Contains(out, `unknown --shell value "tabs"`) {
+		t.Fatalf("expected an unknown shell error, got:\n%s", out)
+	}
+	out, code := runMainCode(t, "new", "feature", "orders", "--shell", "tabs")
+	if code != 1 || !strings.Contains(out, `unexpected argument "tabs" for 'new feature'`) {
+		t.Fatalf("expected the stray shell value to be rejected, got code %d:\n%s", code, out)
+	}
+	mustNotExist(t, filepath.Join("lib", "features", "orders"))
+}
+
+func TestNewPageRejectsRouteCollisions(t *testing.T) {
+	withTempDir(t)
+	_ = runMain(t, "new", "page", "orders", "detail")
+
+	out, code := runMainCode(t, "new", "page", "products", "detail")
+	if code == 0 || !strings.Contains(out, "kDetailPage in page_names.dart is already used by feature orders; set route_names: feature") {
+		t.Fatalf("expected a constant collision error, got code %d:\n%s", code, out)
+	}
+	mustNotExist(t, filepath.Join("lib", "features", "products", "presentation", "pages", "detail_page.dart"))
+
+	_ = runMain(t, "new", "page", "orders", "orders")
+	out, code = runMainCode(t, "new", "page", "orders", "detail", "--parent", "orders")
+	if code == 0 || !strings.Contains(out, "kDetailPage is already declared with path /detail, not /orders/detail") {
+		t.Fatalf("expected a path mismatch error, got code %d:\n%s", code, out)
+	}
+
+	names := filepath.Join("lib", "core", "page_names.dart")
+	if err := os.WriteFile(names, []byte(mustReadFile(t, names)+"const kLegacyPage = '/item/:slug';\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	out, code = runMainCode(t, "new", "page", "orders", "item", "--path-param", "id:int")
+	if code == 0 || !strings.Contains(out, "Route path /item/:id of kItemPage conflicts with kLegacyPage (/item/:slug)") {
+		t.Fatalf("expected a path conflict error, got code %d:\n%s", code, out)
+	}
+
+	// the same page again is not a collision
+	if out, code := runMainCode(t, "new", "page", "orders", "detail"); code != 0 {
+		t.Fatalf("expected re-running new page to succeed:\n%s", out)
+	}
+}
+
+func TestFeatureRouteNames(t *testing.T) {
+	withTempDir(t)
+	if err := os.WriteFile(".farch.yaml", []byte("route_names: feature\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	_ = runMain(t, "new", "page", "orders", "detail", "--path-param", "id:int")
+	_ = runMain(t, "new", "page", "products", "detail")
+	_ = runMain(t, "new", "page", "products", "products")
+
+	names := mustReadFile(t, filepath.Join("lib", "core", "page_names.dart"))
+	for _, expect := range []string{
+		"const kOrdersDetailPage = '/orders/detail/:id';",
+		"const kProductsDetailPage = '/products/detail';",
+		"const kProductsPage = '/products';",
+	} {
+		if !strings.Contains(names, expect) {
+			t.Fatalf("expected %q, got:\n%s", expect, names)
+		}
+	}
+	router := mustReadFile(t, filepath.Join("lib", "core", "router.dart"))
+	for _, expect := range []string{
+		"import '../features/orders/presentation/pages/detail_page.dart' as orders;",
+		"import '../features/products/presentation/pages/detail_page.dart' as products;",
+		"GoRoute(path: kOrdersDetailPage, builder: (context, state) => orders.DetailPage(id: int.parse(state.pathParameters['id']!))),",
+		"GoRoute(path: kProductsDetailPage, builder: (context, state) => const products.DetailPage()),",
+	} {
+		if !strings.Contains(router, expect) {
+			t.Fatalf("expected %q, got:\n%s", expect, router)
+		}
+	}
+
+	_ = runMain(t, "new", "page", "products", "reviews", "--parent", "detail")
+	_ = runMain(t, "rename", "feature", "products", "catalog")
+	names = mustReadFile(t, filepath.Join("lib", "core", "page_names.dart"))
+	router = mustReadFile(t, filepath.Join("lib", "core", "router.dart"))
+	for _, expect := range []string{
+		"const kCatalogDetailPage = '/catalog/detail';",
+		"const kCatalogReviewsPage = '/catalog/detail/reviews';",
+		"const kCatalogPage = '/catalog';",
+	} {
+		if !strings.Contains(names, expect) {
+			t.Fatalf("expected %q, got:\n%s", expect, names)
+		}
+	}
+	if !strings.Contains(router, "import '../features/catalog/presentation/pages/detail_page.dart' as catalog;") ||
+		!strings.Contains(router, "GoRoute(path: kCatalogDetailPage, builder: (context, state) => const catalog.DetailPage(), routes: [") ||
+		!strings.Contains(router, "// AUTO_ROUTES:CatalogDetailPage") {
+		t.Fatalf("expected the routes to follow the feature, got:\n%s", router)
+	}
+
+	_ = runMain(t, "remove", "feature", "orders", "--force")
+	router = mustReadFile(t, filepath.Join("lib", "core", "router.dart"))
+	if strings.Contains(router, "orders.DetailPage") || !strings.Contains(router, "catalog.DetailPage") {
+		t.Fatalf("expected only the orders route to be removed, got:\n%s", router)
+	}
+}
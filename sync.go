@@ -0,0 +1,149 @@
+// sync.go
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// pageFieldRe matches a field of a page class.
+var pageFieldRe = regexp.MustCompile(`(?m)^  final (\S+) (\w+);$`)
+
+// syncRoutes reconciles router.dart and page_names.dart with the pages on
+// disk: routes, imports and constants of pages that no longer exist are
+// removed, and pages without them get them.
+func syncRoutes() {
+	reconcileRoutes()
+	if n := len(changes.pending()); n == 0 {
+		fmt.Println("✅ Routes are in sync with the pages on disk")
+	} else {
+		fmt.Printf("🎯 Synced routes in %d file(s)\n", n)
+	}
+}
+
+// checkRoutes reports what sync routes would change without writing it and
+// returns the exit code: 1 when routes are out of sync.
+func checkRoutes() int {
+	fmt.Println("🔍 Checking routes against the pages on disk (nothing is written)")
+	changes = newChangeSet()
+	reconcileRoutes()
+	if changes.failed() {
+		return 1
+	}
+	pending := changes.pending()
+	if len(pending) == 0 {
+		fmt.Println("✅ Routes are in sync with the pages on disk")
+		return 0
+	}
+	fmt.Printf("⚠️  Routes are out of sync in %d file(s); run: sync routes\n", len(pending))
+	for _, path := range pending {
+		fmt.Printf("   ~ %s\n", path)
+	}
+	return 1
+}
+
+// reconcileRoutes stages the route, import and constant changes that bring
+// router.dart and page_names.dart in line with the pages on disk.
+func reconcileRoutes() {
+	type page struct{ feature, name string }
+	var pages []page
+	classes := map[string]bool{}
+	constants := map[string]bool{}
+	for _, feature := range listFeatures() {
+		for _, name := range featurePages(feature) {
+			pages = append(pages, page{feature, name})
+			classes[pageRouteClass(feature, name)] = true
+			constants[pageConstName(feature, name)] = true
+		}
+	}
+
+	removeOrphanRoutes(classes)
+	removeOrphanConstants(constants)
+
+	for _, p := range pages {
+		params, err := pageRouteParams(p.feature, p.name)
+		if err != nil {
+			fmt.Printf("⚠️  Skipped %sPage: %v\n", pascalCase(p.name), err)
+			continue
+		}
+		constName := pageConstName(p.feature, p.name)
+		if _, ok := pageConstValue(constName); !ok {
+			addPageConstant(p.feature, p.name, pageRoutePath(p.feature, p.name, params))
+		}
+		appendRoute(p.feature, p.name, params, "")
+	}
+}
+
+// removeOrphanRoutes drops the GoRoutes of router.dart building page classes
+// that no longer exist, and imports of page files that are gone.
+func removeOrphanRoutes(classes map[string]bool) {
+	routerFile := cfg.Paths.Router
+	if !changes.exists(routerFile) {
+		return
+	}
+	suffix := cfg.Suffixes.Page + ".dart"
+	pkg := packageName()
+	editDirectives(routerFile, func(d dartDirective) bool {
+		if d.Keyword != dirImport || !strings.HasSuffix(d.URI(), suffix) {
+			return false
+		}
+		target := resolveDartImport(routerFile, d.URI(), pkg)
+		if target == "" || changes.exists(target) {
+			return false
+		}
+		fmt.Printf("➖ Removed import of missing page %s\n", d.URI())
+		return true
+	})
+	editGoRoutes(routerFile, func(r goRoute) bool {
+		if !strings.HasSuffix(r.Class, "Page") || classes[r.Class] {
+			return false
+		}
+		fmt.Printf("➖ Removed route of missing page %s\n", r.Class)
+		return true
+	})
+}
+
+// removeOrphanConstants drops the page_names.dart constants no page owns.
+func removeOrphanConstants(constants map[string]bool) {
+	if !changes.exists(cfg.Paths.PageNames) {
+		return
+	}
+	editLines(cfg.Paths.PageNames, func(trimmed string) bool {
+		m := pageConstRe.FindStringSubmatch(trimmed)
+		if m == nil || constants[m[1]] {
+			return false
+		}
+		fmt.Printf("➖ Removed constant %s of a missing page\n", m[1])
+		return true
+	})
+}
+
+// pageRouteParams reads the route parameters of a page from its fields.
+// When the page already has a constant, its ":name" segments tell the path
+// parameters; otherwise required fields go in the path and nullable ones in
+// the query.
+func pageRouteParams(feature, pageName string) ([]routeParam, error) {
+	data, err := changes.readFile(pageFilePath(feature, pageName))
+	if err != nil {
+		return nil, err
+	}
+	path, hasPath := pageConstValue(pageConstName(feature, pageName))
+	var params []routeParam
+	for _, m := range pageFieldRe.FindAllStringSubmatch(string(data), -1) {
+		p := routeParam{dartField: dartField{Name: m[2], Type: m[1]}}
+		if _, ok := routeParamParsers[p.BaseType()]; !ok {
+			return nil, fmt.Errorf("field %s has type %s, which a route cannot pass", p.Name, p.Type)
+		}
+		if hasPath {
+			p.Query = !strings.Contains(path+"/", "/:"+p.Name+"/")
+		} else {
+			p.Query = p.Nullable()
+		}
+		if p.Query && !p.Nullable() {
+			return nil, fmt.Errorf("field %s is required but not in the path %s", p.Name, path)
+		}
+		params = append(params, p)
+	}
+	return params, nil
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSyncRoutes(t *testing.T) {
+	withTempDir(t)
+	_ = runMain(t, "new", "page", "orders", "detail", "--path-param", "id:int")
+	_ = runMain(t, "new", "page", "orders", "history")
+	if err := os.Remove(pageFilePath("orders", "history")); err != nil {
+		t.Fatal(err)
+	}
+	mustWriteFile(t, pageFilePath("orders", "review"), `import 'package:flutter/material.dart';
+
+class ReviewPage extends StatelessWidget {
+  final int id;
+  final String? sort;
+
+  const ReviewPage({super.key, required this.id, this.sort});
+
+  @override
+  Widget build(BuildContext context) => const Placeholder();
+}
+`)
+	router := filepath.Join("lib", "core", "router.dart")
+	names := filepath.Join("lib", "core", "page_names.dart")
+	before := mustReadFile(t, router)
+
+	out, code := runMainCode(t, "sync", "routes", "--check")
+	if code != 1 || !strings.Contains(out, "Routes are out of sync in 2 file(s)") || strings.Contains(out, "Rolled back") {
+		t.Fatalf("expected --check to fail on drift, got code %d:\n%s", code, out)
+	}
+	if mustReadFile(t, router) != before {
+		t.Fatalf("expected --check to write nothing")
+	}
+
+	_ = runMain(t, "sync", "routes")
+	routerContent := mustReadFile(t, router)
+	namesContent := mustReadFile(t, names)
+	for _, gone := range []string{"history_page.dart", "HistoryPage"} {
+		if strings.Contains(routerContent, gone) {
+			t.Fatalf("expected %s to be removed, got:\n%s", gone, routerContent)
+		}
+	}
+	if strings.Contains(namesContent, "kHistoryPage") {
+		t.Fatalf("expected kHistoryPage to be removed, got:\n%s", namesContent)
+	}
+	for _, expect := range []string{
+		"import '../features/orders/presentation/pages/review_page.dart';",
+		"ReviewPage(id: int.parse(state.pathParameters['id']!), sort: state.uri.queryParameters['sort'])",
+		"GoRoute(path: kDetailPage, builder: (context, state) => DetailPage(id: int.parse(state.pathParameters['id']!))),",
+	} {
+		if !strings.Contains(routerContent, expect) {
+			t.Fatalf("expected %q in router.dart, got:\n%s", expect, routerContent)
+		}
+	}
+	if !strings.Contains(namesContent, "const kReviewPage = '/review/:id';") {
+		t.Fatalf("expected kReviewPage, got:\n%s", namesContent)
+	}
+
+	if out, code := runMainCode(t, "sync", "routes", "--check"); code != 0 || !strings.Contains(out, "✅ Routes are in sync") {
+		t.Fatalf("expected no drift after sync, got code %d:\n%s", code, out)
+	}
+}
+
+func TestSyncRoutesKeepsFormattedRoutes(t *testing.T) {
+	withTempDir(t)
+	_ = runMain(t, "new", "page", "orders", "detail", "--path-param", "id:int")
+	_ = runMain(t, "new", "page", "orders", "history")
+
+	router := filepath.Join("lib", "core", "router.dart")
+	formatted := strings.NewReplacer(
+		"GoRoute(path: kDetailPage, builder: (context, state) => DetailPage(id: int.parse(state.pathParameters['id']!))),",
+		"GoRoute(\n      path: kDetailPage,\n      builder: (context, state) =>\n          DetailPage(id: int.parse(state.pathParameters['id']!)),\n    ),",
+		"GoRoute(path: kHistoryPage, builder: (context, state) => const HistoryPage()),",
+		"GoRoute(\n      path: kHistoryPage,\n      builder: (context, state) => const HistoryPage(),\n    ),",
+	).Replace(mustReadFile(t, router))
+	if strings.Count(formatted, "      builder: (context, state) =>") != 2 {
+		t.Fatalf("expected both generated routes in router.dart, got:\n%s", formatted)
+	}
+	mustWriteFile(t, router, formatted)
+
+	if out, code := runMainCode(t, "sync", "routes", "--check"); code != 0 || !strings.Contains(out, "✅ Routes are in sync") {
+		t.Fatalf("expected formatted routes to count as registered, got code %d:\n%s", code, out)
+	}
+	_ = runMain(t, "sync", "routes")
+	if got := mustReadFile(t, router); got != formatted {
+		t.Fatalf("expected sync to leave formatted routes alone, got:\n%s", got)
+	}
+
+	if err := os.Remove(pageFilePath("orders", "history")); err != nil {
+		t.Fatal(err)
+	}
+	_ = runMain(t, "sync", "routes")
+	got := mustReadFile(t, router)
+	if strings.Contains(got, "History") || !strings.Contains(got, "path: kDetailPage,") {
+		t.Fatalf("expected only the formatted history route to go, got:\n%s", got)
+	}
+}
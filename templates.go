@@ -0,0 +1,172 @@
+// templates.go
+package main
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+	"unicode"
+)
+
+//go:embed templates/*.tmpl
+var builtinTemplates embed.FS
+
+// templateOverrideDir is where a project can drop its own copies of the
+// built-in templates, keeping the same file names.
+var templateOverrideDir = filepath.Join(".farch", "templates")
+
+// templateData is passed to every Dart template. Generators fill in only the
+// fields their template uses.
+type templateData struct {
+	Feature          string
+	Name             string
+	Subject          string
+	PartFile         string
+	Kind             string
+	Provider         string
+	ProviderVar      string
+	ProviderImport   string
+	RepositoryImport string // kept for ejected templates; repository_impl imports it through Imports
+	Fields           []dartField
+	NeedsFoundation  bool
+	EntityImport     string
+	ModelImport      string
+	WidgetImport     string
+	SampleJSON       []string
+	Imports          []string
+	Repository       string
+	DataSource       string
+	Methods          []apiMethod
+	Method           *apiMethod
+	Usecase          string
+	UsecaseCall      string
+	Result           string
+}
+
+var templateFuncs = template.FuncMap{
+	"pascal": pascalCase,
+	"camel":  camelCase,
+	"snake":  snakeCase,
+	"plural": plural,
+	"lower":  strings.ToLower,
+	"upper":  strings.ToUpper,
+}
+
+// renderTemplate executes the named template (e.g. "entity.dart.tmpl"),
+// preferring a project override in .farch/templates over the embedded default.
+func renderTemplate(name string, data templateData) (string, error) {
+	src, err := os.ReadFile(filepath.Join(templateOverrideDir, name))
+	if os.IsNotExist(err) {
+		src, err = builtinTemplates.ReadFile("templates/" + name)
+	}
+	if err != nil {
+		return "", fmt.Errorf("template %s: %w", name, err)
+	}
+
+	tmpl, err := template.New(name).Funcs(templateFuncs).Option("missingkey=error").Parse(string(src))
+	if err != nil {
+		return "", fmt.Errorf("template %s: %w", name, err)
+	}
+	var b bytes.Buffer
+	if err := tmpl.Execute(&b, data); err != nil {
+		return "", fmt.Errorf("template %s: %w", name, err)
+	}
+	return b.String(), nil
+}
+
+// builtinTemplateNames lists the embedded template file names in order.
+func builtinTemplateNames() []string {
+	entries, _ := fs.ReadDir(builtinTemplates, "templates")
+	names := make([]string, 0, len(entries))
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ejectTemplates writes the built-in templates to .farch/templates so they can
+// be edited. Existing files are left alone. With no names, every template is
+// ejected.
+func ejectTemplates(names []string) {
+	if len(names) == 0 {
+		names = builtinTemplateNames()
+	}
+	changes.mkdirAll(templateOverrideDir)
+
+	for _, name := range names {
+		if !strings.HasSuffix(name, ".tmpl") {
+			name += ".dart.tmpl"
+		}
+		data, err := builtinTemplates.ReadFile("templates/" + name)
+		if err != nil {
+			failf("Unknown template %s. Available: %s", name, strings.Join(builtinTemplateNames(), ", "))
+			continue
+		}
+		writeFile(filepath.Join(templateOverrideDir, name), string(data))
+	}
+}
+
+// snakeCase turns "MyPage", "myPage" or "my-page" into "my_page"
+func snakeCase(s string) string {
+	var b strings.Builder
+	runes := []rune(s)
+	for i, r := range runes {
+		switch {
+		case r == '-' || r == ' ' || r == '_':
+			if b.Len() > 0 && !strings.HasSuffix(b.String(), "_") {
+				b.WriteRune('_')
+			}
+		case unicode.IsUpper(r):
+			prevLower := i > 0 && (unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1]))
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			prevUpper := i > 0 && unicode.IsUpper(runes[i-1])
+			if b.Len() > 0 && !strings.HasSuffix(b.String(), "_") && (prevLower || (prevUpper && nextLower)) {
+				b.WriteRune('_')
+			}
+			b.WriteRune(unicode.ToLower(r))
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return strings.Trim(b.String(), "_")
+}
+
+// plural returns a naive English plural: "order" -> "orders", "category" -> "categories"
+func plural(s string) string {
+	lower := strings.ToLower(s)
+	switch {
+	case s == "":
+		return s
+	case strings.HasSuffix(lower, "y") && len(s) > 1 && !strings.ContainsRune("aeiou", rune(lower[len(lower)-2])):
+		return s[:len(s)-1] + "ies"
+	case strings.HasSuffix(lower, "s"), strings.HasSuffix(lower, "x"), strings.HasSuffix(lower, "z"),
+		strings.HasSuffix(lower, "ch"), strings.HasSuffix(lower, "sh"):
+		return s + "es"
+	default:
+		return s + "s"
+	}
+}
+
+// singular is the rough inverse of plural: "categories" -> "category",
+// "addresses" -> "address", "items" -> "item".
+func singular(s string) string {
+	lower := strings.ToLower(s)
+	switch {
+	case strings.HasSuffix(lower, "ies") && len(s) > 3:
+		return s[:len(s)-3] + "y"
+	case strings.HasSuffix(lower, "sses"), strings.HasSuffix(lower, "xes"), strings.HasSuffix(lower, "zes"),
+		strings.HasSuffix(lower, "ches"), strings.HasSuffix(lower, "shes"):
+		return s[:len(s)-2]
+	case strings.HasSuffix(lower, "s") && !strings.HasSuffix(lower, "ss") && len(s) > 1:
+		return s[:len(s)-1]
+	default:
+		return s
+	}
+}
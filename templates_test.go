@@ -0,0 +1,63 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestTemplateHelpers(t *testing.T) {
+	tests := []struct {
+		fn     func(string) string
+		in     string
+		expect string
+	}{
+		{fn: snakeCase, in: "UserProfile", expect: "user_profile"},
+		{fn: snakeCase, in: "userProfile", expect: "user_profile"},
+		{fn: snakeCase, in: "HTTPClient", expect: "http_client"},
+		{fn: snakeCase, in: "my-page", expect: "my_page"},
+		{fn: plural, in: "order", expect: "orders"},
+		{fn: plural, in: "category", expect: "categories"},
+		{fn: plural, in: "day", expect: "days"},
+		{fn: plural, in: "box", expect: "boxes"},
+	}
+	for _, tt := range tests {
+		if got := tt.fn(tt.in); got != tt.expect {
+			t.Fatalf("%q: expected %q, got %q", tt.in, tt.expect, got)
+		}
+	}
+}
+
+func TestTemplateOverrideIsUsed(t *testing.T) {
+	withTempDir(t)
+
+	if err := os.MkdirAll(templateOverrideDir, 0755); err != nil {
+		t.Fatalf("mkdir failed: %v", err)
+	}
+	override := "// custom\nclass {{pascal .Name}}Entity {}\n"
+	if err := os.WriteFile(filepath.Join(templateOverrideDir, "entity.dart.tmpl"), []byte(override), 0644); err != nil {
+		t.Fatalf("write override failed: %v", err)
+	}
+
+	_ = runMain(t, "new", "entity", "orders", "line_item")
+
+	content := mustReadFile(t, filepath.Join("lib", "features", "orders", "domain", "entities", "line_item.dart"))
+	if content != "// custom\nclass LineItemEntity {}\n" {
+		t.Fatalf("override template not used, got:\n%s", content)
+	}
+}
+
+func TestTemplatesEjectWritesDefaults(t *testing.T) {
+	withTempDir(t)
+
+	_ = runMain(t, "templates", "eject")
+	for _, name := range builtinTemplateNames() {
+		mustExist(t, filepath.Join(templateOverrideDir, name))
+	}
+
+	out := runMain(t, "templates", "eject", "nope")
+	if !strings.Contains(out, "Unknown template nope.dart.tmpl") {
+		t.Fatalf("expected unknown template error, got:\n%s", out)
+	}
+}
@@ -0,0 +1,134 @@
+// usecase.go
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// createRepositoryUsecase writes a usecase that calls a new method of an
+// existing repository, declaring the method on the domain repository and
+// stubbing it in the implementation.
+func createRepositoryUsecase(feature, usecaseName, repoName string, params []dartField, returns string) {
+	domainFile := filepath.Join("lib", "features", feature, "domain", "repositories", repoName+cfg.Suffixes.Repository+".dart")
+	dataFile := filepath.Join("lib", "features", feature, "data", "repositories", repoName+cfg.Suffixes.RepositoryImpl+".dart")
+	if !changes.exists(domainFile) {
+		failf("Repository %s not found; create it with: new repository %s %s", domainFile, feature, repoName)
+		return
+	}
+
+	m := apiMethod{Name: camelCase(usecaseName), Usecase: usecaseName, Returns: returns}
+	for _, f := range params {
+		m.Params = append(m.Params, apiParam{Name: f.Name, Key: f.Name, Type: f.Type, Required: !f.Nullable()})
+	}
+	types := []string{m.Returns}
+	for _, p := range m.Params {
+		types = append(types, p.Type)
+	}
+
+	dir := filepath.Join("lib", "features", feature, "domain", "usecases")
+	changes.mkdirAll(dir)
+	file := filepath.Join(dir, usecaseName+cfg.Suffixes.Usecase+".dart")
+	ensureCore()
+	imports := append(classImports(file, typeFields(types...), entityPath(feature)), dartRelImport(file, domainFile))
+	imports = append(imports, coreImports(file, coreUsecaseFile, coreResultFile)...)
+	sort.Strings(imports)
+	writeTemplate(file, "usecase.dart.tmpl", templateData{
+		Feature:    feature,
+		Name:       usecaseName,
+		Repository: repoName,
+		Method:     &m,
+		Imports:    imports,
+	})
+	writeTest(feature, filepath.Join("domain", "usecases", usecaseName+"_usecase_test.dart"), testStub("usecase "+usecaseName))
+	registerUsecase(feature, usecaseName, file, repoName)
+
+	addRepositoryMethod(domainFile, pascalCase(repoName)+"Repository", m,
+		fmt.Sprintf("  Future<Result<%s>> %s(%s);", m.Returns, m.Name, m.Signature()),
+		append(classImports(domainFile, typeFields(types...), entityPath(feature)), coreImports(domainFile, coreResultFile)...))
+	if !changes.exists(dataFile) {
+		fmt.Printf("⚠️  %s not found; add an override of %s yourself\n", dataFile, m.Name)
+		return
+	}
+	addRepositoryMethod(dataFile, pascalCase(repoName)+"RepositoryImpl", m,
+		fmt.Sprintf("  @override\n  Future<Result<%s>> %s(%s) async {\n    // TODO: implement %s\n    throw UnimplementedError();\n  }", m.Returns, m.Name, m.Signature(), m.Name),
+		append(classImports(dataFile, typeFields(types...), entityPath(feature)), coreImports(dataFile, coreResultFile)...))
+}
+
+// addRepositoryMethod adds member to class in path, with the imports it
+// needs, unless the class already declares the method.
+func addRepositoryMethod(path, class string, m apiMethod, member string, imports []string) {
+	data, err := changes.readFile(path)
+	if err != nil {
+		failf("Failed to read %s: %v", path, err)
+		return
+	}
+	content := string(data)
+	if regexp.MustCompile(`\b` + m.Name + `\s*\(`).MatchString(content) {
+		fmt.Printf("⚠️  %s already declares %s (kept)\n", path, m.Name)
+		return
+	}
+	updated, ok := insertClassMember(content, class, member)
+	if !ok {
+		failf("Could not find class %s in %s", class, path)
+		return
+	}
+	for _, imp := range imports {
+		updated = addImport(updated, imp)
+	}
+	if err := changes.writeFile(path, []byte(updated)); err != nil {
+		failf("Failed to update %s: %v", path, err)
+		return
+	}
+	fmt.Printf("➕ Added %s to %s\n", m.Name, path)
+}
+
+// addImport adds an import of uri, starting an import block set apart by a
+// blank line when the file has none.
+func addImport(content, uri string) string {
+	return insertImportDirective(content, "import '"+uri+"';")
+}
+
+// insertClassMember appends member to the body of class, replacing a lone
+// "// TODO" placeholder. Members are separated by a blank line unless both
+// are one-line declarations.
+func insertClassMember(content, class, member string) (string, bool) {
+	loc := regexp.MustCompile(`\bclass\s+` + regexp.QuoteMeta(class) + `\b[^{]*\{`).FindStringIndex(content)
+	if loc == nil {
+		return content, false
+	}
+	open := loc[1] - 1
+	depth, end := 0, -1
+	for i := open; i < len(content) && end == -1; i++ {
+		switch content[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				end = i
+			}
+		}
+	}
+	if end == -1 {
+		return content, false
+	}
+
+	body := strings.TrimRight(content[open+1:end], " \t\n")
+	trimmed := strings.TrimSpace(body)
+	if trimmed == "" || (strings.HasPrefix(trimmed, "// TODO") && !strings.Contains(trimmed, "\n")) {
+		body = ""
+	}
+	switch {
+	case body == "":
+		body = "\n" + member + "\n"
+	case strings.Contains(member, "\n") || strings.HasSuffix(body, "}"):
+		body += "\n\n" + member + "\n"
+	default:
+		body += "\n" + member + "\n"
+	}
+	return content[:open+1] + body + content[end:], true
+}
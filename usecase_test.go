@@ -0,0 +1,71 @@
+package main
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNewUsecaseWithRepository(t *testing.T) {
+	withTempDir(t)
+	_ = runMain(t, "new", "repository", "orders", "orders")
+	_ = runMain(t, "new", "usecase", "orders", "get_order", "--repo", "orders", "--params", "id:int, note:String?", "--returns", "Order")
+
+	usecase := mustReadFile(t, filepath.Join("lib", "features", "orders", "domain", "usecases", "get_order.dart"))
+	for _, expect := range []string{
+		"import '../repositories/orders_repository.dart';",
+		"  const GetOrder(this.repository);",
+		"import '../../../../core/usecases/usecase.dart';",
+		"class GetOrder extends UseCase<Order, GetOrderParams> {",
+		"  Future<Result<Order>> call(GetOrderParams params) {",
+		"    return repository.getOrder(params.id, note: params.note);",
+		"class GetOrderParams {",
+		"    required this.id,",
+		"    this.note,",
+	} {
+		if !strings.Contains(usecase, expect) {
+			t.Fatalf("expected usecase to contain %q, got:\n%s", expect, usecase)
+		}
+	}
+
+	repo := mustReadFile(t, filepath.Join("lib", "features", "orders", "domain", "repositories", "orders_repository.dart"))
+	if want := "import '../../../../core/utils/result.dart';\n\nabstract class OrdersRepository {\n  Future<Result<Order>> getOrder(int id, {String? note});\n}\n"; repo != want {
+		t.Fatalf("unexpected repository:\n%s", repo)
+	}
+	impl := mustReadFile(t, filepath.Join("lib", "features", "orders", "data", "repositories", "orders_repository_impl.dart"))
+	if !strings.Contains(impl, "  @override\n  Future<Result<Order>> getOrder(int id, {String? note}) async {\n    // TODO: implement getOrder\n    throw UnimplementedError();\n  }\n}") {
+		t.Fatalf("expected stub override, got:\n%s", impl)
+	}
+	if strings.Contains(impl, "TODO: implement methods") {
+		t.Fatalf("expected placeholder to be replaced, got:\n%s", impl)
+	}
+
+	out := runMain(t, "new", "usecase", "orders", "get_order_again", "--repo", "missing")
+	if !strings.Contains(out, "Repository lib/features/orders/domain/repositories/missing_repository.dart not found") {
+		t.Fatalf("expected missing repository error, got:\n%s", out)
+	}
+}
+
+func TestInsertClassMember(t *testing.T) {
+	content := "class A {\n  void a() {\n    if (x) {}\n  }\n}\n\nclass B {}\n"
+	got, ok := insertClassMember(content, "A", "  void b() {}")
+	if !ok {
+		t.Fatalf("class A not found")
+	}
+	want := "class A {\n  void a() {\n    if (x) {}\n  }\n\n  void b() {}\n}\n\nclass B {}\n"
+	if got != want {
+		t.Fatalf("unexpected result:\n%s", got)
+	}
+	if _, ok := insertClassMember(content, "C", "  int c;"); ok {
+		t.Fatalf("expected missing class to be reported")
+	}
+}
+
+func TestSplitSpecList(t *testing.T) {
+	got := splitSpecList([]string{"id:int, tags:Map<String, int>", "note:String?"})
+	want := []string{"id:int", "tags:Map<String, int>", "note:String?"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+}
@@ -0,0 +1,84 @@
+// widget.go
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// Widget kinds selected by the new widget flags.
+const (
+	widgetStateless = "stateless"
+	widgetStateful  = "stateful"
+	widgetConsumer  = "consumer"
+)
+
+// createWidget writes a widget under presentation/widgets with a widget test
+// that pumps it. With export set, the widget is also exported from the
+// feature barrel.
+func createWidget(feature, widgetName, kind string, export bool) {
+	dir := filepath.Join("lib", "features", feature, "presentation", "widgets")
+	changes.mkdirAll(dir)
+
+	file := filepath.Join(dir, widgetName+cfg.Suffixes.Widget+".dart")
+	data := templateData{Feature: feature, Name: widgetName, Kind: kind}
+	writeTemplate(file, "widget.dart.tmpl", data)
+
+	var ok bool
+	if data.WidgetImport, ok = testImport(file); !ok {
+		return
+	}
+	content, err := renderTemplate("widget_test.dart.tmpl", data)
+	if err != nil {
+		failf("Failed rendering widget test: %v", err)
+		return
+	}
+	writeTest(feature, filepath.Join("presentation", "widgets", widgetName+"_widget_test.dart"), content)
+
+	if export {
+		addBarrelExport(feature, file)
+	}
+}
+
+// featureBarrel returns the file that re-exports a feature's public files.
+func featureBarrel(feature string) string {
+	return filepath.Join("lib", "features", feature, feature+".dart")
+}
+
+// addBarrelExport exports target from the feature barrel, creating the
+// barrel when needed. Directives are sorted afterwards.
+func addBarrelExport(feature, target string) {
+	barrel := featureBarrel(feature)
+	uri := dartRelImport(barrel, target)
+	line := "export '" + uri + "';"
+
+	content := ""
+	if changes.exists(barrel) {
+		data, err := changes.readFile(barrel)
+		if err != nil {
+			failf("Failed to read %s: %v", barrel, err)
+			return
+		}
+		content = string(data)
+		if hasDirective(content, line) {
+			fmt.Printf("⚠️  %s already exports %s (kept)\n", barrel, filepath.Base(target))
+			return
+		}
+	}
+
+	offset := len(content)
+	if ds := parseDirectives(content); len(ds) > 0 {
+		offset = lineAfter(content, ds[len(ds)-1].End)
+	}
+	content = sortDirectives(insertLineAt(content, offset, line))
+	if !strings.HasSuffix(content, "\n") {
+		content += "\n"
+	}
+
+	if err := changes.writeFile(barrel, []byte(content)); err != nil {
+		failf("Failed to update %s: %v", barrel, err)
+		return
+	}
+	fmt.Printf("📤 Exported %s from %s\n", filepath.Base(target), barrel)
+}
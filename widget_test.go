@@ -0,0 +1,68 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestNewWidgetKinds(t *testing.T) {
+	withTempDir(t)
+	widgets := filepath.Join("lib", "features", "orders", "presentation", "widgets")
+	tests := filepath.Join("test", "features", "orders", "presentation", "widgets")
+
+	cases := []struct {
+		name   string
+		flag   string
+		widget string
+		test   string
+	}{
+		{name: "order_card", widget: "class OrderCardWidget extends StatelessWidget {", test: "const MaterialApp(home: Scaffold(body: OrderCardWidget())),"},
+		{name: "counter", flag: "--stateful", widget: "class _CounterWidgetState extends State<CounterWidget> {", test: "expect(find.byType(CounterWidget), findsOneWidget);"},
+		{name: "summary", flag: "--consumer", widget: "Widget build(BuildContext context, WidgetRef ref) {", test: "const ProviderScope("},
+	}
+	for _, tc := range cases {
+		args := []string{"new", "widget", "orders", tc.name}
+		if tc.flag != "" {
+			args = append(args, tc.flag)
+		}
+		_ = runMain(t, args...)
+		widget := mustReadFile(t, filepath.Join(widgets, tc.name+"_widget.dart"))
+		if !strings.Contains(widget, tc.widget) {
+			t.Fatalf("widget %s should contain %q, got:\n%s", tc.name, tc.widget, widget)
+		}
+		test := mustReadFile(t, filepath.Join(tests, tc.name+"_widget_test.dart"))
+		if !strings.Contains(test, "await tester.pumpWidget(") || !strings.Contains(test, tc.test) {
+			t.Fatalf("widget test %s should pump the widget and contain %q, got:\n%s", tc.name, tc.test, test)
+		}
+	}
+	mustNotExist(t, featureBarrel("orders"))
+}
+
+func TestNewWidgetExportsFromBarrel(t *testing.T) {
+	withTempDir(t)
+	_ = runMain(t, "new", "widget", "orders", "order_card", "--export")
+	_ = runMain(t, "new", "widget", "orders", "badge", "--export")
+	out := runMain(t, "new", "widget", "orders", "badge", "--export")
+	if !strings.Contains(out, "already exports badge_widget.dart") {
+		t.Fatalf("expected a repeated export to be kept, got:\n%s", out)
+	}
+
+	barrel := mustReadFile(t, filepath.Join("lib", "features", "orders", "orders.dart"))
+	expect := "export 'presentation/widgets/badge_widget.dart';\nexport 'presentation/widgets/order_card_widget.dart';\n"
+	if barrel != expect {
+		t.Fatalf("unexpected barrel:\n%s", barrel)
+	}
+}
+
+func TestNewWidgetNeedsPackageName(t *testing.T) {
+	withTempDir(t)
+	mustWriteFile(t, "pubspec.yaml", "description: no name\n")
+
+	out, code := runMainCode(t, "new", "widget", "orders", "badge")
+	if code != 1 || !strings.Contains(out, `pubspec.yaml has no package name; add "name: <package>"`) {
+		t.Fatalf("expected a missing package name to fail, got code %d:\n%s", code, out)
+	}
+	mustNotExist(t, filepath.Join("lib", "features", "orders", "presentation", "widgets", "badge_widget.dart"))
+	mustNotExist(t, filepath.Join("test", "features", "orders", "presentation", "widgets", "badge_widget_test.dart"))
+}